@@ -0,0 +1,83 @@
+package transmission
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Totals sums up a list of torrents, see Torrents.Aggregate
+type Totals struct {
+	Count         int
+	Size          uint64 // SizeWhenDone
+	Have          uint64 // verified and unverified data
+	LeftUntilDone uint64
+	Downloaded    uint64 // DownloadedEver
+	Uploaded      uint64 // UploadedEver
+	RateDownload  uint64
+	RateUpload    uint64
+	// Ratio is uploaded over downloaded data, counting what was there
+	// before for torrents that never downloaded anything like the daemon
+	// does. It is -1 if nothing was downloaded or verified.
+	Ratio float64
+
+	ByStatus  map[Status]int
+	ByTracker map[string]int // by announce host, torrents with several trackers count once for each
+	ByDir     map[string]int
+	ByLabel   map[string]int
+}
+
+// Aggregate computes totals over the torrents
+func (t Torrents) Aggregate() Totals {
+	totals := Totals{
+		ByStatus:  make(map[Status]int),
+		ByTracker: make(map[string]int),
+		ByDir:     make(map[string]int),
+		ByLabel:   make(map[string]int),
+	}
+
+	var ratioBase uint64
+	for _, tr := range t {
+		totals.Count++
+		totals.Size += tr.SizeWhenDone
+		totals.Have += tr.Have()
+		totals.LeftUntilDone += tr.LeftUntilDone
+		totals.Downloaded += tr.DownloadedEver
+		totals.Uploaded += tr.UploadedEver
+		totals.RateDownload += tr.RateDownload
+		totals.RateUpload += tr.RateUpload
+		if tr.DownloadedEver > 0 {
+			ratioBase += tr.DownloadedEver
+		} else {
+			ratioBase += tr.HaveValid
+		}
+
+		totals.ByStatus[tr.Status]++
+		totals.ByDir[tr.DownloadDir]++
+		for _, label := range tr.Labels {
+			totals.ByLabel[label]++
+		}
+		seen := make(map[string]bool)
+		for _, tk := range tr.Trackers {
+			host := trackerHost(tk.Announce)
+			if host != "" && !seen[host] {
+				seen[host] = true
+				totals.ByTracker[host]++
+			}
+		}
+	}
+
+	totals.Ratio = -1
+	if ratioBase > 0 {
+		totals.Ratio = float64(totals.Uploaded) / float64(ratioBase)
+	}
+	return totals
+}
+
+// trackerHost returns the lower case host name of an announce URL
+func trackerHost(announce string) string {
+	u, err := url.Parse(announce)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
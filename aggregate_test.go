@@ -0,0 +1,65 @@
+package transmission
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAggregate(t *testing.T) {
+	Convey("Test aggregating torrents", t, func() {
+		torrents := Torrents{
+			{ID: 1, Status: StatusSeeding, SizeWhenDone: 1000, HaveValid: 1000, DownloadedEver: 1000, UploadedEver: 3000,
+				RateUpload: 50, DownloadDir: "/data/linux", Labels: []string{"linux"},
+				Trackers: []tracker{{Announce: "https://Tracker.example.org/announce"}, {Announce: "udp://tracker.example.org:6969"}}},
+			{ID: 2, Status: StatusDownloading, SizeWhenDone: 4000, HaveValid: 500, HaveUnchecked: 500, LeftUntilDone: 3000,
+				DownloadedEver: 1000, RateDownload: 200, DownloadDir: "/data/tv", Labels: []string{"tv", "linux"},
+				Trackers: []tracker{{Announce: "http://other.example.net/announce"}}},
+			{ID: 3, Status: StatusSeeding, SizeWhenDone: 2000, HaveValid: 2000, UploadedEver: 1000, DownloadDir: "/data/linux"},
+		}
+
+		totals := torrents.Aggregate()
+		So(totals.Count, ShouldEqual, 3)
+		So(totals.Size, ShouldEqual, uint64(7000))
+		So(totals.Have, ShouldEqual, uint64(4000))
+		So(totals.LeftUntilDone, ShouldEqual, uint64(3000))
+		So(totals.Downloaded, ShouldEqual, uint64(2000))
+		So(totals.Uploaded, ShouldEqual, uint64(4000))
+		So(totals.RateDownload, ShouldEqual, uint64(200))
+		So(totals.RateUpload, ShouldEqual, uint64(50))
+		// torrent 3 was seeded from local data, so it counts what it has
+		So(totals.Ratio, ShouldEqual, 1.0)
+
+		So(totals.ByStatus, ShouldResemble, map[Status]int{StatusSeeding: 2, StatusDownloading: 1})
+		So(totals.ByTracker, ShouldResemble, map[string]int{"tracker.example.org": 1, "other.example.net": 1})
+		So(totals.ByDir, ShouldResemble, map[string]int{"/data/linux": 2, "/data/tv": 1})
+		So(totals.ByLabel, ShouldResemble, map[string]int{"linux": 2, "tv": 1})
+	})
+
+	Convey("Test aggregating nothing", t, func() {
+		totals := Torrents{}.Aggregate()
+		So(totals.Count, ShouldEqual, 0)
+		So(totals.Ratio, ShouldEqual, -1.0)
+		So(totals.ByStatus, ShouldBeEmpty)
+	})
+}
+
+func TestGetStats(t *testing.T) {
+	Convey("Test session-stats over JSON-RPC", t, func() {
+		var last map[string]interface{}
+		server := jsonrpcServer(18, `{"active_torrent_count":2,"torrent_count":5,"download_speed":1024,
+  "cumulative_stats":{"downloaded_bytes":4096,"seconds_active":60},"current_stats":{"session_count":1}}`, &last)
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		stats, err := client.GetStats()
+		So(err, ShouldBeNil)
+		So(last["method"], ShouldEqual, "session_stats")
+		So(stats.ActiveTorrentCount, ShouldEqual, 2)
+		So(stats.TorrentCount, ShouldEqual, 5)
+		So(stats.DownloadSpeed, ShouldEqual, uint64(1024))
+		So(stats.CumulativeStats.DownloadedBytes, ShouldEqual, uint64(4096))
+		So(stats.CumulativeActiveTime(), ShouldEqual, "1m0s")
+		So(stats.CurrentStats.SessionCount, ShouldEqual, 1)
+	})
+}
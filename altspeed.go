@@ -0,0 +1,126 @@
+package transmission
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays is the day bitmask used by the alt-speed scheduler
+type Weekdays int
+
+const (
+	Sunday Weekdays = 1 << iota
+	Monday
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+
+	Workweek = Monday | Tuesday | Wednesday | Thursday | Friday
+	Weekend  = Saturday | Sunday
+	EveryDay = Workweek | Weekend
+)
+
+// NewWeekdays returns the mask holding days
+func NewWeekdays(days ...time.Weekday) Weekdays {
+	var w Weekdays
+	for _, d := range days {
+		w |= 1 << uint(d)
+	}
+	return w
+}
+
+// Has reports whether d is in the mask
+func (w Weekdays) Has(d time.Weekday) bool {
+	return w&(1<<uint(d)) != 0
+}
+
+// Days returns the days in the mask, starting with Sunday
+func (w Weekdays) Days() []time.Weekday {
+	days := make([]time.Weekday, 0, 7)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if w.Has(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+func (w Weekdays) String() string {
+	switch w & EveryDay {
+	case 0:
+		return "never"
+	case EveryDay:
+		return "every day"
+	case Workweek:
+		return "workweek"
+	case Weekend:
+		return "weekend"
+	}
+	names := make([]string, 0, 7)
+	for _, d := range w.Days() {
+		names = append(names, d.String()[:3])
+	}
+	return strings.Join(names, ",")
+}
+
+// AltSpeedSchedule turns alt-speed mode on from Begin to End on Days.
+// Begin and End are minutes after midnight.
+type AltSpeedSchedule struct {
+	Enabled bool
+	Begin   int
+	End     int
+	Days    Weekdays
+}
+
+// minutesPerDay bounds the schedule's Begin and End
+const minutesPerDay = 24 * 60
+
+func (s AltSpeedSchedule) validate() error {
+	if s.Begin < 0 || s.Begin >= minutesPerDay {
+		return fmt.Errorf("transmission: alt-speed begin %d is not a minute of the day", s.Begin)
+	}
+	if s.End < 0 || s.End >= minutesPerDay {
+		return fmt.Errorf("transmission: alt-speed end %d is not a minute of the day", s.End)
+	}
+	if s.Days&^EveryDay != 0 {
+		return fmt.Errorf("transmission: alt-speed days %d is not a day mask", int(s.Days))
+	}
+	return nil
+}
+
+// AltSpeedSchedule returns the session's alt-speed schedule
+func (s *Session) AltSpeedSchedule() AltSpeedSchedule {
+	return AltSpeedSchedule{
+		Enabled: s.AltSpeedTimeEnabled,
+		Begin:   s.AltSpeedTimeBegin,
+		End:     s.AltSpeedTimeEnd,
+		Days:    s.AltSpeedTimeDay,
+	}
+}
+
+// SetAltSpeedEnabled turns alt-speed (turtle) mode on or off
+func (ac *TransmissionClient) SetAltSpeedEnabled(enabled bool) error {
+	return ac.SetSession(SessionSettings{AltSpeedEnabled: &enabled})
+}
+
+// SetAltSpeedLimits sets the alt-speed mode limits in KB/s
+func (ac *TransmissionClient) SetAltSpeedLimits(down, up int) error {
+	return ac.SetSession(SessionSettings{AltSpeedDown: &down, AltSpeedUp: &up})
+}
+
+// SetAltSpeedSchedule configures the daemon's alt-speed scheduler. Begin
+// and End must be within 0..1439 and Days within EveryDay.
+func (ac *TransmissionClient) SetAltSpeedSchedule(schedule AltSpeedSchedule) error {
+	if err := schedule.validate(); err != nil {
+		return err
+	}
+	return ac.SetSession(SessionSettings{
+		AltSpeedTimeEnabled: &schedule.Enabled,
+		AltSpeedTimeBegin:   &schedule.Begin,
+		AltSpeedTimeEnd:     &schedule.End,
+		AltSpeedTimeDay:     &schedule.Days,
+	})
+}
@@ -0,0 +1,87 @@
+package transmission
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWeekdays(t *testing.T) {
+	Convey("Test building and reading day masks", t, func() {
+		So(int(Sunday), ShouldEqual, 1)
+		So(int(Saturday), ShouldEqual, 64)
+		So(int(Workweek), ShouldEqual, 62)
+		So(int(Weekend), ShouldEqual, 65)
+		So(int(EveryDay), ShouldEqual, 127)
+
+		w := NewWeekdays(time.Monday, time.Wednesday)
+		So(w, ShouldEqual, Monday|Wednesday)
+		So(w.Has(time.Monday), ShouldBeTrue)
+		So(w.Has(time.Tuesday), ShouldBeFalse)
+		So(w.Days(), ShouldResemble, []time.Weekday{time.Monday, time.Wednesday})
+	})
+
+	Convey("Test day masks are readable", t, func() {
+		So(Weekdays(0).String(), ShouldEqual, "never")
+		So(EveryDay.String(), ShouldEqual, "every day")
+		So(Workweek.String(), ShouldEqual, "workweek")
+		So(Weekend.String(), ShouldEqual, "weekend")
+		So((Monday | Friday).String(), ShouldEqual, "Mon,Fri")
+	})
+}
+
+func TestAltSpeed(t *testing.T) {
+	server, requests := rpcServer(17, map[string]string{
+		"session-get": `{"rpc-version":17,"alt-speed-enabled":true,"alt-speed-down":50,"alt-speed-up":10,
+  "alt-speed-time-enabled":true,"alt-speed-time-begin":540,"alt-speed-time-end":1020,"alt-speed-time-day":62}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	last := func() map[string]interface{} {
+		reqs := requests()
+		return reqs[len(reqs)-1].Arguments
+	}
+
+	Convey("Test reading the schedule", t, func() {
+		session, err := client.GetSession()
+		So(err, ShouldBeNil)
+		So(session.AltSpeedEnabled, ShouldBeTrue)
+		So(session.AltSpeedSchedule(), ShouldResemble, AltSpeedSchedule{
+			Enabled: true, Begin: 9 * 60, End: 17 * 60, Days: Workweek,
+		})
+	})
+
+	Convey("Test toggling alt-speed", t, func() {
+		So(client.SetAltSpeedEnabled(false), ShouldBeNil)
+		So(last(), ShouldResemble, map[string]interface{}{"alt-speed-enabled": false})
+	})
+
+	Convey("Test setting alt-speed limits", t, func() {
+		So(client.SetAltSpeedLimits(100, 20), ShouldBeNil)
+		So(last(), ShouldResemble, map[string]interface{}{"alt-speed-down": float64(100), "alt-speed-up": float64(20)})
+	})
+
+	Convey("Test setting the schedule", t, func() {
+		So(client.SetAltSpeedSchedule(AltSpeedSchedule{Enabled: true, Begin: 0, End: 6 * 60, Days: Weekend}), ShouldBeNil)
+		So(last(), ShouldResemble, map[string]interface{}{
+			"alt-speed-time-enabled": true,
+			"alt-speed-time-begin":   float64(0),
+			"alt-speed-time-end":     float64(360),
+			"alt-speed-time-day":     float64(65),
+		})
+	})
+
+	Convey("Test invalid schedules are not sent", t, func() {
+		sent := len(requests())
+		for _, s := range []AltSpeedSchedule{
+			{Begin: -1, End: 60, Days: EveryDay},
+			{Begin: 0, End: 24 * 60, Days: EveryDay},
+			{Begin: 0, End: 60, Days: EveryDay + 1},
+		} {
+			So(client.SetAltSpeedSchedule(s), ShouldNotBeNil)
+		}
+		So(len(requests()), ShouldEqual, sent)
+	})
+}
@@ -0,0 +1,163 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DefaultBatchWorkers is how many requests a Batch has in flight at once
+// when the daemon can't take them in a single round trip
+const DefaultBatchWorkers = 4
+
+// Batch queues requests to send together. Daemons speaking JSON-RPC 2.0
+// get them as a single batch request, older ones get them concurrently
+// over Workers connections. Replies are matched to requests by tag.
+type Batch struct {
+	Workers int
+
+	client *TransmissionClient
+	reqs   []request
+}
+
+// BatchResult is the outcome of one request of a Batch
+type BatchResult struct {
+	Method    string
+	Arguments interface{} // the arguments as queued
+	Err       error
+
+	proto protocol
+	reply json.RawMessage
+}
+
+// Decode decodes the reply's arguments into out, e.g. a *TorrentGetResponse
+// for a torrent-get. It returns Err if the request failed.
+func (r BatchResult) Decode(out interface{}) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	return r.proto.unmarshal(r.reply, out)
+}
+
+// NewBatch returns an empty batch sending through the client
+func (ac *TransmissionClient) NewBatch() *Batch {
+	return &Batch{Workers: DefaultBatchWorkers, client: ac}
+}
+
+// Add queues method with args, one of the request types in requests.go or
+// nil, and returns its index in the results of Execute
+func (b *Batch) Add(method string, args interface{}) int {
+	b.reqs = append(b.reqs, request{Method: method, Arguments: args})
+	return len(b.reqs) - 1
+}
+
+// AddCommand queues cmd like Add
+func (b *Batch) AddCommand(cmd *Command) int {
+	return b.Add(cmd.Method, cmd.Arguments)
+}
+
+// Len returns the number of queued requests
+func (b *Batch) Len() int {
+	return len(b.reqs)
+}
+
+// Execute sends every queued request and returns one result per request,
+// in the order they were added. The error is only set when nothing could
+// be sent at all; failures of single requests, including replies other
+// than "success", are in their result.
+func (b *Batch) Execute() ([]BatchResult, error) {
+	results := make([]BatchResult, len(b.reqs))
+	if len(b.reqs) == 0 {
+		return results, nil
+	}
+
+	proto, err := b.client.protocol()
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make([]request, len(b.reqs))
+	for i, req := range b.reqs {
+		results[i].Method = req.Method
+		results[i].Arguments = req.Arguments
+		reqs[i] = req
+		reqs[i].Tag = b.client.nextTag()
+	}
+
+	if batcher, ok := proto.(batchProtocol); ok {
+		return results, b.sendBatch(proto, batcher, reqs, results)
+	}
+	b.sendConcurrently(proto, reqs, results)
+	return results, nil
+}
+
+// sendBatch sends all requests in one round trip
+func (b *Batch) sendBatch(proto protocol, batcher batchProtocol, reqs []request, results []BatchResult) error {
+	body, err := batcher.encodeBatch(reqs)
+	if err != nil {
+		return err
+	}
+	output, err := b.client.apiclient.Post(string(body))
+	if err != nil {
+		return err
+	}
+	responses, err := batcher.decodeBatch(output)
+	if err != nil {
+		return err
+	}
+
+	byTag := make(map[int]response, len(responses))
+	for _, res := range responses {
+		byTag[res.Tag] = res
+	}
+	for i, req := range reqs {
+		res, ok := byTag[req.Tag]
+		if !ok {
+			results[i].Err = fmt.Errorf("transmission: %s: no reply for tag %d", req.Method, req.Tag)
+			continue
+		}
+		results[i].setReply(proto, req, res)
+	}
+	return nil
+}
+
+// sendConcurrently sends the requests one by one over a bounded worker pool
+func (b *Batch) sendConcurrently(proto protocol, reqs []request, results []BatchResult) {
+	workers := b.Workers
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				res, err := b.client.roundTrip(proto, reqs[i])
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].setReply(proto, reqs[i], res)
+			}
+		}()
+	}
+	for i := range reqs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// setReply keeps res for Decode, turning an unsuccessful result into an *RPCError
+func (r *BatchResult) setReply(proto protocol, req request, res response) {
+	r.proto, r.reply = proto, res.Arguments
+	if res.Result != "success" {
+		r.Err = &RPCError{Method: req.Method, Result: res.Result}
+	}
+}
@@ -0,0 +1,148 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBatchLegacy(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var cmd Command
+		json.NewDecoder(req.Body).Decode(&cmd)
+		if cmd.Method == "session-get" {
+			fmt.Fprintf(res, `{"arguments":{"rpc-version":17},"result":"success","tag":%d}`, cmd.Tag)
+			return
+		}
+
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		// answer later ids first so replies come back out of order
+		time.Sleep(time.Duration(10-cmd.Arguments.Ids[0]) * time.Millisecond)
+
+		if cmd.Arguments.Ids[0] == 3 {
+			fmt.Fprintf(res, `{"arguments":{},"result":"no such torrent","tag":%d}`, cmd.Tag)
+			return
+		}
+		fmt.Fprintf(res, `{"arguments":{"torrents":[{"id":%d}]},"result":"success","tag":%d}`, cmd.Arguments.Ids[0], cmd.Tag)
+	}))
+	defer server.Close()
+
+	Convey("Test commands are sent concurrently and matched by tag", t, func() {
+		client, _ := NewClient(WithURL(server.URL))
+		batch := client.NewBatch()
+		batch.Workers = 2
+		for id := 1; id <= 6; id++ {
+			req := TorrentGetRequest{Fields: []string{"id"}, IDs: []int{id}}
+			So(batch.Add("torrent-get", req), ShouldEqual, id-1)
+		}
+
+		results, err := batch.Execute()
+		So(err, ShouldBeNil)
+		So(len(results), ShouldEqual, 6)
+		for i, result := range results {
+			if i == 2 {
+				So(result.Err, ShouldNotBeNil)
+				So(result.Err.(*RPCError).Result, ShouldEqual, "no such torrent")
+				continue
+			}
+			var out TorrentGetResponse
+			So(result.Decode(&out), ShouldBeNil)
+			So(out.Torrents[0].ID, ShouldEqual, i+1)
+		}
+		So(atomic.LoadInt32(&maxInFlight), ShouldBeLessThan, 3)
+	})
+}
+
+func TestBatchMethods(t *testing.T) {
+	Convey("Test any method can be batched with its request type", t, func() {
+		server, requests := rpcServer(17, map[string]string{
+			"torrent-add": `{"torrent-added":{"id":7,"name":"Sintel"}}`,
+		})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		batch := client.NewBatch()
+		batch.Workers = 1
+		batch.Add("torrent-add", TorrentAddRequest{Filename: "magnet:?xt=urn:btih:abc", Paused: true})
+		batch.Add("torrent-remove", TorrentRemoveRequest{IDs: []int{1}, DeleteLocalData: true})
+		batch.Add("torrent-set-location", TorrentSetLocationRequest{IDs: []int{2}, Location: "/new", Move: true})
+		batch.AddCommand(NewGetTorrentsCmd())
+
+		results, err := batch.Execute()
+		So(err, ShouldBeNil)
+		var added TorrentAddResponse
+		So(results[0].Decode(&added), ShouldBeNil)
+		So(added.TorrentAdded.ID, ShouldEqual, 7)
+		So(results[3].Method, ShouldEqual, "torrent-get")
+
+		reqs := requests()
+		So(len(reqs), ShouldEqual, 4)
+		So(reqs[0].Arguments["paused"], ShouldEqual, true)
+		So(reqs[1].Arguments["delete-local-data"], ShouldEqual, true)
+		So(reqs[2].Arguments["location"], ShouldEqual, "/new")
+		So(reqs[3].Arguments["fields"], ShouldNotBeEmpty)
+	})
+}
+
+func TestBatchJSONRPC(t *testing.T) {
+	var posts int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&posts, 1)
+		var batch []struct {
+			Method string `json:"method"`
+			Params struct {
+				Ids []int `json:"ids"`
+			} `json:"params"`
+			ID int `json:"id"`
+		}
+		json.NewDecoder(req.Body).Decode(&batch)
+
+		// reply in reverse order, leaving out the last request
+		fmt.Fprint(res, "[")
+		for i := len(batch) - 2; i >= 0; i-- {
+			fmt.Fprintf(res, `{"jsonrpc":"2.0","result":{"torrents":[{"id":%d,"download_dir":"/d%d"}]},"id":%d}`,
+				batch[i].Params.Ids[0], batch[i].Params.Ids[0], batch[i].ID)
+			if i > 0 {
+				fmt.Fprint(res, ",")
+			}
+		}
+		fmt.Fprint(res, "]")
+	}))
+	defer server.Close()
+
+	Convey("Test commands are sent as one JSON-RPC batch", t, func() {
+		client, _ := NewClient(WithURL(server.URL), WithProtocol(ProtocolJSONRPC))
+		client.caps = &Capabilities{RPCVersion: 18}
+
+		batch := client.NewBatch()
+		for id := 1; id <= 3; id++ {
+			cmd := NewGetTorrentsCmd()
+			cmd.Arguments.Ids = []int{id}
+			batch.AddCommand(cmd)
+		}
+
+		results, err := batch.Execute()
+		So(err, ShouldBeNil)
+		So(atomic.LoadInt32(&posts), ShouldEqual, int32(1))
+		var out TorrentGetResponse
+		So(results[0].Decode(&out), ShouldBeNil)
+		So(out.Torrents[0].DownloadDir, ShouldEqual, "/d1")
+		So(results[1].Decode(&out), ShouldBeNil)
+		So(out.Torrents[0].DownloadDir, ShouldEqual, "/d2")
+		So(results[2].Err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,147 @@
+package transmission
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxRPCVersion is the newest rpc-version this package knows about
+const maxRPCVersion = 18
+
+// ErrUnsupported is returned when the daemon is too old for a request
+var ErrUnsupported = errors.New("transmission: unsupported by daemon")
+
+// Feature is an RPC method or argument only available in some daemon versions
+type Feature int
+
+const (
+	FeatureRenamePath Feature = iota
+	FeatureFreeSpace
+	FeatureLabels
+	FeatureBandwidthGroups
+	FeatureTrackerList
+	FeatureJSONRPC
+	FeaturePortTestIPProtocol
+	FeatureAddLabels
+)
+
+var features = map[Feature]struct {
+	name       string
+	rpcVersion int // first rpc-version with the feature
+}{
+	FeatureRenamePath:         {"torrent-rename-path", 15},
+	FeatureFreeSpace:          {"free-space", 15},
+	FeatureLabels:             {"labels", 16},
+	FeatureBandwidthGroups:    {"bandwidth groups", 17},
+	FeatureTrackerList:        {"trackerList", 17},
+	FeatureJSONRPC:            {"JSON-RPC 2.0", jsonrpcVersion},
+	FeaturePortTestIPProtocol: {"port-test ipProtocol", 18},
+	FeatureAddLabels:          {"torrent-add labels", 17},
+}
+
+func (f Feature) String() string {
+	return features[f].name
+}
+
+// fieldVersions lists the torrent-get fields that older daemons don't know
+var fieldVersions = map[string]int{
+	"trackerList": features[FeatureTrackerList].rpcVersion,
+	"group":       features[FeatureBandwidthGroups].rpcVersion,
+	"labels":      features[FeatureLabels].rpcVersion,
+}
+
+// Capabilities describes the daemon the client is connected to
+type Capabilities struct {
+	RPCVersion        int    `json:"rpc-version"`
+	RPCVersionMinimum int    `json:"rpc-version-minimum"`
+	Version           string `json:"version"`
+}
+
+// Supports reports whether the daemon has feature f
+func (c Capabilities) Supports(f Feature) bool {
+	return c.RPCVersion >= features[f].rpcVersion
+}
+
+// require returns an error wrapping ErrUnsupported if the daemon lacks f
+func (c Capabilities) require(f Feature) error {
+	if c.Supports(f) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s needs rpc-version %d, %s has %d",
+		ErrUnsupported, f, features[f].rpcVersion, c.Version, c.RPCVersion)
+}
+
+// filterFields drops the torrent fields the daemon doesn't know about
+func (c Capabilities) filterFields(fields []string) []string {
+	filtered := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if version, ok := fieldVersions[f]; ok && c.RPCVersion < version {
+			continue
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered
+}
+
+// Capabilities returns what the daemon supports, connecting first if needed
+func (ac *TransmissionClient) Capabilities() (Capabilities, error) {
+	if _, err := ac.protocol(); err != nil {
+		return Capabilities{}, err
+	}
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return *ac.caps, nil
+}
+
+// require returns an error wrapping ErrUnsupported if the daemon lacks f
+func (ac *TransmissionClient) require(f Feature) error {
+	caps, err := ac.Capabilities()
+	if err != nil {
+		return err
+	}
+	return caps.require(f)
+}
+
+func (ac *TransmissionClient) connected() bool {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.caps != nil
+}
+
+// protocol returns the dialect to use. The first time it's called it asks
+// the daemon for its versions and, for ProtocolAuto, picks the dialect.
+func (ac *TransmissionClient) protocol() (protocol, error) {
+	ac.mu.RLock()
+	proto, caps, mode := ac.proto, ac.caps, ac.protoMode
+	ac.mu.RUnlock()
+	if caps != nil {
+		return proto, nil
+	}
+
+	// unless a dialect was forced this is a legacy session-get, which
+	// daemons speaking JSON-RPC 2.0 still understand
+	caps = &Capabilities{}
+	res, err := ac.roundTrip(proto, request{Method: "session-get"})
+	if err != nil {
+		return nil, err
+	}
+	if res.Result != "success" {
+		return nil, &RPCError{Method: "session-get", Result: res.Result}
+	}
+	if err := proto.unmarshal(res.Arguments, caps); err != nil {
+		return nil, err
+	}
+	if caps.RPCVersionMinimum > maxRPCVersion {
+		return nil, fmt.Errorf("transmission: daemon %s needs rpc-version %d, this package speaks up to %d",
+			caps.Version, caps.RPCVersionMinimum, maxRPCVersion)
+	}
+
+	if mode == ProtocolAuto && caps.Supports(FeatureJSONRPC) {
+		proto = jsonrpcProtocol{}
+	}
+
+	ac.mu.Lock()
+	ac.proto, ac.caps = proto, caps
+	ac.mu.Unlock()
+	return proto, nil
+}
@@ -0,0 +1,77 @@
+package transmission
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCapabilities(t *testing.T) {
+	Convey("Test versions are read on connect", t, func() {
+		server, _ := rpcServer(17, map[string]string{"torrent-get": `{"torrents":[]}`})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		caps, err := client.Capabilities()
+		So(err, ShouldBeNil)
+		So(caps.RPCVersion, ShouldEqual, 17)
+		So(caps.RPCVersionMinimum, ShouldEqual, 1)
+		So(caps.Version, ShouldEqual, "test")
+		So(caps.Supports(FeatureTrackerList), ShouldBeTrue)
+		So(caps.Supports(FeatureJSONRPC), ShouldBeFalse)
+	})
+
+	Convey("Test unsupported methods are not sent", t, func() {
+		server, requests := rpcServer(14, map[string]string{"torrent-get": `{"torrents":[]}`})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		err := client.RenameTorrentPath(1, "a", "b")
+		So(errors.Is(err, ErrUnsupported), ShouldBeTrue)
+		err = client.SetTrackers(1, [][]string{{"http://a/announce"}})
+		So(errors.Is(err, ErrUnsupported), ShouldBeTrue)
+		So(len(requests()), ShouldEqual, 0)
+	})
+
+	Convey("Test labels are not added on daemons without them", t, func() {
+		server, requests := rpcServer(16, map[string]string{
+			"torrent-get": `{"torrents":[]}`,
+			"torrent-add": `{"torrent-added":{"id":1,"name":"a"}}`,
+		})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		cmd := NewAddCmdByURL("http://example.org/a.torrent")
+		cmd.SetLabels("tv")
+		_, err := client.ExecuteAddCommand(cmd)
+		So(errors.Is(err, ErrUnsupported), ShouldBeTrue)
+		So(len(requests()), ShouldEqual, 0)
+
+		_, err = client.ExecuteAddCommand(NewAddCmdByURL("http://example.org/a.torrent"))
+		So(err, ShouldBeNil)
+		So(len(requests()), ShouldEqual, 1)
+	})
+
+	Convey("Test supported methods are sent", t, func() {
+		server, requests := rpcServer(17, map[string]string{"torrent-get": `{"torrents":[]}`})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		err := client.SetTrackers(1, [][]string{{"http://a/announce", "http://b/announce"}, {"http://c/announce"}})
+		So(err, ShouldBeNil)
+		So(len(requests()), ShouldEqual, 1)
+		So(requests()[0].Method, ShouldEqual, "torrent-set")
+	})
+
+	Convey("Test unknown fields are not requested", t, func() {
+		server, requests := rpcServer(16, map[string]string{"torrent-get": `{"torrents":[]}`})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		_, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(requests()[0].Arguments["fields"], ShouldNotContain, "trackerList")
+		So(requests()[0].Arguments["fields"], ShouldContain, "trackers")
+	})
+}
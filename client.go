@@ -1,84 +1,205 @@
 package transmission
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
+// ErrUnauthorized is returned when the daemon rejects the credentials
+var ErrUnauthorized = errors.New("transmission: unauthorized")
+
+// StatusError is returned when the daemon answers with an unexpected HTTP status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+// Unwrap lets errors.Is match ErrUnauthorized on a 401
+func (e *StatusError) Unwrap() error {
+	if e.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("transmission: unexpected response %s", e.Status)
+	}
+	return fmt.Sprintf("transmission: unexpected response %s: %s", e.Status, e.Body)
+}
+
 type ApiClient struct {
-	url      string
-	username string
-	password string
-	token    string
-	client   http.Client
+	url    string
+	client *http.Client
+	logger Logger
+	retry  RetryPolicy
+
+	mu          sync.RWMutex // guards token and credentials
+	token       string
+	credentials CredentialProvider
+	// refreshMu makes sure only one goroutine learns the session id at a
+	// time, the others wait for its result
+	refreshMu sync.Mutex
 }
 
-func NewClient(url, username, password string) *ApiClient {
-	return &ApiClient{url: url, username: username, password: password}
+func NewApiClient(url, username, password string) *ApiClient {
+	return &ApiClient{
+		url:         url,
+		client:      &http.Client{},
+		logger:      nopLogger{},
+		credentials: StaticCredentials(username, password),
+	}
+}
+
+// SetCredentials replaces the provider used to authenticate requests
+func (ac *ApiClient) SetCredentials(provider CredentialProvider) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.credentials = provider
 }
 
 func (ac *ApiClient) CreateClient(apiToken string) {
-	ac.client = http.Client{}
+	ac.client = &http.Client{}
 }
 
+// Post sends body to the daemon, retrying according to the client's
+// RetryPolicy when the request fails or the daemon answers with a 5xx
 func (ac *ApiClient) Post(body string) ([]byte, error) {
-	authRequest, err := ac.authRequest("POST", body)
-	if err != nil {
-		return make([]byte, 0), err
+	backoff := ac.retry.Backoff
+	for attempt := 1; ; attempt++ {
+		resBody, err := ac.post(body)
+		if err == nil || attempt >= ac.retry.Attempts || !retryable(err) {
+			return resBody, err
+		}
+
+		ac.logger.Printf("transmission: attempt %d failed, retrying in %v: %v", attempt, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if ac.retry.MaxBackoff > 0 && backoff > ac.retry.MaxBackoff {
+			backoff = ac.retry.MaxBackoff
+		}
 	}
-	res, err := ac.client.Do(authRequest)
+}
+
+func (ac *ApiClient) post(body string) ([]byte, error) {
+	res, err := ac.send(body, ac.currentToken())
 	if err != nil {
 		return make([]byte, 0), err
 	}
-	defer res.Body.Close()
-	if res.StatusCode == 409 {
-		ac.getToken()
-		authRequest, err := ac.authRequest("POST", body)
-		if err != nil {
-			return make([]byte, 0), err
-		}
-		res, err = ac.client.Do(authRequest)
-		if err != nil {
-			return make([]byte, 0), err
-		}
-	}
+	defer drain(res)
+
 	resBody, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return make([]byte, 0), err
 	}
+	if res.StatusCode != http.StatusOK {
+		return make([]byte, 0), &StatusError{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+			Body:       strings.TrimSpace(string(resBody)),
+		}
+	}
 	return resBody, nil
 }
 
-func (ac *ApiClient) getToken() error {
-	req, err := http.NewRequest("POST", ac.url, strings.NewReader(""))
-	if err != nil {
-		return err
+// send posts body with token, taking a new session id from a 409 reply and
+// sending again with it. While no id is known one caller does the 409
+// exchange and the others wait to use the id it got.
+func (ac *ApiClient) send(body, token string) (*http.Response, error) {
+	if token == "" {
+		ac.refreshMu.Lock()
+		if token = ac.currentToken(); token == "" {
+			res, err := ac.exchange(body, token)
+			ac.refreshMu.Unlock()
+			return res, err
+		}
+		ac.refreshMu.Unlock()
+	}
+	return ac.exchange(body, token)
+}
+
+// exchange posts body with token and, on a 409, stores the session id the
+// reply carries and sends body again with it
+func (ac *ApiClient) exchange(body, token string) (*http.Response, error) {
+	res, err := ac.do("POST", body, token)
+	if err != nil || res.StatusCode != http.StatusConflict {
+		return res, err
 	}
+	token = res.Header.Get("X-Transmission-Session-Id")
+	drain(res)
+	ac.setToken(token)
+	ac.logger.Printf("transmission: got new session id %q", token)
+	return ac.do("POST", body, token)
+}
+
+func (ac *ApiClient) currentToken() string {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.token
+}
+
+func (ac *ApiClient) setToken(token string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.token = token
+}
 
-	req.SetBasicAuth(ac.username, ac.password)
-	res, err := ac.client.Do(req)
+func (ac *ApiClient) do(method string, body string, token string) (*http.Response, error) {
+	authRequest, err := ac.authRequest(method, body, token)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer res.Body.Close()
-	ac.token = res.Header.Get("X-Transmission-Session-Id")
-	return nil
+	return ac.client.Do(authRequest)
 }
 
-func (ac *ApiClient) authRequest(method string, body string) (*http.Request, error) {
-	if ac.token == "" {
-		err := ac.getToken()
-		if err != nil {
-			return &http.Request{}, err
-		}
+func (ac *ApiClient) authRequest(method string, body string, token string) (*http.Request, error) {
+	ac.mu.RLock()
+	provider := ac.credentials
+	ac.mu.RUnlock()
+	username, password, err := provider.Credentials()
+	if err != nil {
+		return &http.Request{}, err
 	}
+
 	req, err := http.NewRequest(method, ac.url, strings.NewReader(body))
 	if err != nil {
 		return &http.Request{}, err
 	}
-	req.Header.Add("X-Transmission-Session-Id", ac.token)
+	if token != "" {
+		req.Header.Add("X-Transmission-Session-Id", token)
+	}
 
-	req.SetBasicAuth(ac.username, ac.password)
+	req.SetBasicAuth(username, password)
 	return req, nil
 }
+
+// drain reads whatever is left of the body and closes it so the
+// underlying connection can be reused
+func drain(res *http.Response) {
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+}
+
+// retryable reports whether a failed request is worth sending again: the
+// daemon answered with a 5xx or the request didn't get through. Errors from
+// the CredentialProvider or building the request won't go away by retrying.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op != "parse"
+	}
+	return false
+}
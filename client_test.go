@@ -1,9 +1,12 @@
 package transmission
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/go-martini/martini"
@@ -40,7 +43,7 @@ func cSetup() {
 	cServer = httptest.NewServer(cMux)
 
 	// github client configured to use test server
-	client = NewClient(cServer.URL+"/transmission/rpc", "test", "test")
+	client = NewApiClient(cServer.URL+"/transmission/rpc", "test", "test")
 }
 
 func cTeardown() {
@@ -57,10 +60,69 @@ func TestPost(t *testing.T) {
 	})
 
 	Convey("Test when auth is incorrect", t, func() {
-		fakeClient := NewClient(cServer.URL, "testfake", "testfake")
+		fakeClient := NewApiClient(cServer.URL, "testfake", "testfake")
 		output, err := fakeClient.Post("")
-		So(err, ShouldBeNil)
-		So(string(output), ShouldEqual, "Not Authorized\n")
+		So(err, ShouldNotBeNil)
+		So(errors.Is(err, ErrUnauthorized), ShouldBeTrue)
+		So(string(output), ShouldEqual, "")
+
+		statusErr, ok := err.(*StatusError)
+		So(ok, ShouldBeTrue)
+		So(statusErr.StatusCode, ShouldEqual, http.StatusUnauthorized)
 	})
 
+	Convey("Test server errors are returned as errors", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			http.Error(res, "boom", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		output, err := NewApiClient(server.URL, "test", "test").Post("")
+		So(string(output), ShouldEqual, "")
+		So(err, ShouldNotBeNil)
+		So(err.(*StatusError).StatusCode, ShouldEqual, http.StatusInternalServerError)
+		So(err.(*StatusError).Body, ShouldEqual, "boom")
+	})
+
+}
+
+func TestConcurrentPost(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Transmission-Session-Id") != "123" {
+			atomic.AddInt32(&fetches, 1)
+			res.Header().Set("X-Transmission-Session-Id", "123")
+			res.WriteHeader(http.StatusConflict)
+			return
+		}
+		fmt.Fprintf(res, `{"arguments":{},"result":"success"}`)
+	}))
+	defer server.Close()
+
+	Convey("Test a burst of requests fetches the session id once", t, func() {
+		c := NewApiClient(server.URL, "test", "test")
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 20)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Post("{}")
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			So(err, ShouldBeNil)
+		}
+		So(atomic.LoadInt32(&fetches), ShouldEqual, int32(1))
+
+		// once known, the session id is reused without another 409
+		_, err := c.Post("{}")
+		So(err, ShouldBeNil)
+		So(atomic.LoadInt32(&fetches), ShouldEqual, int32(1))
+	})
 }
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tubbebubbe/transmission"
+	"github.com/tubbebubbe/transmission/format"
+)
+
+// env is what commands run with
+type env struct {
+	client *transmission.TransmissionClient
+	out    io.Writer
+	errOut io.Writer
+	format string
+}
+
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.errOut)
+	return fs
+}
+
+func (e *env) print(t *table) error {
+	return t.write(e.out, e.format)
+}
+
+// sortings maps -sort values to ascending and descending Sorting
+var sortings = map[string][2]transmission.Sorting{
+	"id":         {transmission.SortID, transmission.SortRevID},
+	"name":       {transmission.SortName, transmission.SortRevName},
+	"age":        {transmission.SortAge, transmission.SortRevAge},
+	"size":       {transmission.SortSize, transmission.SortRevSize},
+	"progress":   {transmission.SortProgress, transmission.SortRevProgress},
+	"down":       {transmission.SortDownSpeed, transmission.SortRevDownSpeed},
+	"up":         {transmission.SortUpSpeed, transmission.SortRevUpSpeed},
+	"downloaded": {transmission.SortDownloaded, transmission.SortRevDownloaded},
+	"uploaded":   {transmission.SortUploaded, transmission.SortRevUploaded},
+	"ratio":      {transmission.SortRatio, transmission.SortRevRatio},
+}
+
+func runList(e *env, args []string) error {
+	fs := e.flags("list")
+	sortBy := fs.String("sort", "id", "sort by id, name, age, size, progress, down, up, downloaded, uploaded or ratio")
+	reverse := fs.Bool("reverse", false, "reverse the sort order")
+	filter := fs.String("filter", "", `filter expression, e.g. "status:downloading ratio>1"`)
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return errUsage
+	}
+
+	sorting, ok := sortings[*sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort field %q", *sortBy)
+	}
+	var pred transmission.Predicate
+	if *filter != "" {
+		var err error
+		if pred, err = transmission.ParseFilter(*filter); err != nil {
+			return err
+		}
+	}
+
+	if *reverse {
+		e.client.SetSort(sorting[1])
+	} else {
+		e.client.SetSort(sorting[0])
+	}
+	torrents, err := e.client.GetTorrents()
+	if err != nil {
+		return err
+	}
+	if pred != nil {
+		torrents = torrents.Filter(pred)
+	}
+	return e.print(torrentTable(torrents))
+}
+
+func runAdd(e *env, args []string) error {
+	fs := e.flags("add")
+	dir := fs.String("dir", "", "download directory")
+	paused := fs.Bool("paused", false, "don't start the torrents")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		return errUsage
+	}
+
+	t := &table{Header: []string{"ID", "NAME", "HASH"}}
+	var added []transmission.TorrentAdded
+	for _, source := range fs.Args() {
+		cmd, err := addCommand(source)
+		if err != nil {
+			return err
+		}
+		cmd.SetDownloadDir(*dir)
+		cmd.SetPaused(*paused)
+		torrent, err := e.client.ExecuteAddCommand(cmd)
+		var dup *transmission.DuplicateTorrentError
+		if errors.As(err, &dup) {
+			fmt.Fprintf(e.errOut, "%s: already added\n", source)
+		} else if err != nil {
+			return fmt.Errorf("%s: %v", source, err)
+		}
+		added = append(added, torrent)
+		t.add(fmt.Sprint(torrent.ID), torrent.Name, torrent.HashString)
+	}
+	t.Value = added
+	return e.print(t)
+}
+
+// addCommand adds URLs and magnet links by name and reads anything else
+// as a local torrent file
+func addCommand(source string) (*transmission.TorrentAddRequest, error) {
+	for _, prefix := range []string{"magnet:", "http://", "https://"} {
+		if strings.HasPrefix(source, prefix) {
+			return transmission.NewAddCmdByURL(source), nil
+		}
+	}
+	return transmission.NewAddCmdByFile(source)
+}
+
+func runStart(e *env, args []string) error {
+	return forEach(args, e.client.StartAll, e.client.StartTorrent)
+}
+
+func runStop(e *env, args []string) error {
+	return forEach(args, e.client.StopAll, e.client.StopTorrent)
+}
+
+func runVerify(e *env, args []string) error {
+	return forEach(args, e.client.VerifyAll, e.client.VerifyTorrent)
+}
+
+// forEach calls all for "all" and one for each id otherwise
+func forEach(args []string, all func() error, one func(int) (string, error)) error {
+	if len(args) == 1 && args[0] == "all" {
+		return all()
+	}
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := one(id); err != nil {
+			return fmt.Errorf("torrent %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func parseIDs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid torrent id %q", arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func runRemove(e *env, args []string) error {
+	fs := e.flags("remove")
+	deleteData := fs.Bool("delete", false, "also delete the downloaded data")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := e.client.DeleteTorrent(id, *deleteData); err != nil {
+			return fmt.Errorf("torrent %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func runMove(e *env, args []string) error {
+	fs := e.flags("move")
+	find := fs.Bool("find", false, "don't move the data, look for it in dir instead")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 2 {
+		return errUsage
+	}
+	dir := fs.Arg(fs.NArg() - 1)
+	ids, err := parseIDs(fs.Args()[:fs.NArg()-1])
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := e.client.SetTorrentLocation(id, dir, !*find); err != nil {
+			return fmt.Errorf("torrent %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func runStats(e *env, args []string) error {
+	if len(args) > 0 {
+		return errUsage
+	}
+	stats, err := e.client.GetStats()
+	if err != nil {
+		return err
+	}
+
+	t := &table{Header: []string{"STAT", "VALUE"}, Value: stats}
+	t.add("Torrents", fmt.Sprint(stats.TorrentCount))
+	t.add("Active", fmt.Sprint(stats.ActiveTorrentCount))
+	t.add("Paused", fmt.Sprint(stats.PausedTorrentCount))
+	t.add("Download speed", format.Speed(stats.DownloadSpeed))
+	t.add("Upload speed", format.Speed(stats.UploadSpeed))
+	t.add("Downloaded", format.Size(stats.CumulativeStats.DownloadedBytes))
+	t.add("Uploaded", format.Size(stats.CumulativeStats.UploadedBytes))
+	t.add("Active time", stats.CumulativeActiveTime())
+	return e.print(t)
+}
+
+func runSession(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "get":
+		return sessionGet(e, args[1:])
+	case "set":
+		return sessionSet(e, args[1:])
+	}
+	return errUsage
+}
+
+func sessionGet(e *env, keys []string) error {
+	session, err := e.client.GetSession()
+	if err != nil {
+		return err
+	}
+	values, err := sessionValues(session)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+	t := &table{Header: []string{"KEY", "VALUE"}}
+	selected := make(map[string]interface{}, len(keys))
+	for _, k := range keys {
+		v, ok := values[k]
+		if !ok {
+			return fmt.Errorf("unknown session key %q", k)
+		}
+		selected[k] = v
+		t.add(k, fmt.Sprint(v))
+	}
+	t.Value = selected
+	return e.print(t)
+}
+
+// sessionValues returns the session keyed by its RPC names
+func sessionValues(session *transmission.Session) (map[string]interface{}, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var values map[string]interface{}
+	return values, dec.Decode(&values)
+}
+
+func sessionSet(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	var settings transmission.SessionSettings
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("expected key=value, got %q", arg)
+		}
+		if err := setSetting(&settings, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return e.client.SetSession(settings)
+}
+
+// setSetting sets the SessionSettings field whose RPC name is key
+func setSetting(settings *transmission.SessionSettings, key, value string) error {
+	v := reflect.ValueOf(settings).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name != key {
+			continue
+		}
+
+		field := reflect.New(v.Field(i).Type().Elem())
+		if err := parseSetting(field.Elem(), value); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		v.Field(i).Set(field)
+		return nil
+	}
+	return fmt.Errorf("unknown session key %q", key)
+}
+
+func parseSetting(v reflect.Value, s string) error {
+	if e, ok := v.Addr().Interface().(*transmission.Encryption); ok {
+		// the library keeps unknown values from the daemon, settings are checked
+		if *e = transmission.Encryption(s); !e.Known() {
+			return fmt.Errorf("unknown encryption %q", s)
+		}
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
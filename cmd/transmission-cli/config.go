@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tubbebubbe/transmission"
+)
+
+// config holds the connection settings. They are read from the config
+// file first, then the environment, then the command line flags.
+type config struct {
+	URL      string
+	Username string
+	Password string
+	Output   string
+}
+
+// Environment variables read by loadEnv
+const (
+	envURL      = "TRANSMISSION_URL"
+	envUsername = "TRANSMISSION_USERNAME"
+	envPassword = "TRANSMISSION_PASSWORD"
+	envConfig   = "TRANSMISSION_CLI_CONFIG"
+)
+
+func defaultConfig() config {
+	return config{URL: transmission.DefaultURL, Output: "table"}
+}
+
+// defaultConfigPath is used when neither -config nor TRANSMISSION_CLI_CONFIG is set
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "transmission-cli", "config")
+}
+
+// loadFile reads "key = value" lines from path. Blank lines and lines
+// starting with # are skipped. A missing file is only an error if
+// required is set.
+func (c *config) loadFile(path string, required bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%s:%d: expected key = value", path, n)
+		}
+		if err := c.set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
+			return fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *config) set(key, value string) error {
+	switch key {
+	case "url":
+		c.URL = value
+	case "username":
+		c.Username = value
+	case "password":
+		c.Password = value
+	case "output":
+		c.Output = value
+	default:
+		return fmt.Errorf("unknown key %q", key)
+	}
+	return nil
+}
+
+// loadEnv overrides settings with the environment
+func (c *config) loadEnv(getenv func(string) string) {
+	if v := getenv(envURL); v != "" {
+		c.URL = v
+	}
+	if v := getenv(envUsername); v != "" {
+		c.Username = v
+	}
+	if v := getenv(envPassword); v != "" {
+		c.Password = v
+	}
+}
+
+func (c *config) client() (*transmission.TransmissionClient, error) {
+	return transmission.NewClient(
+		transmission.WithURL(c.URL),
+		transmission.WithAuth(c.Username, c.Password),
+	)
+}
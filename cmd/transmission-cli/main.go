@@ -0,0 +1,137 @@
+// Command transmission-cli controls a Transmission daemon.
+//
+// Usage:
+//
+//	transmission-cli [flags] command [arguments]
+//
+// Commands:
+//
+//	list [-sort field] [-reverse] [-filter expr]
+//	add [-dir dir] [-paused] file|url|magnet...
+//	start|stop|verify id...|all
+//	remove [-delete] id...
+//	move [-find] id... dir
+//	stats
+//	session get [key...]
+//	session set key=value...
+//	tui [-interval duration]
+//
+// The tui command shows a live torrent table, listing its keys at the
+// bottom of the screen.
+//
+// The connection is configured by a config file of "key = value" lines
+// (url, username, password, output), overridden by the
+// TRANSMISSION_URL, TRANSMISSION_USERNAME and TRANSMISSION_PASSWORD
+// environment variables, overridden by the flags.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errUsage is returned after the usage has been printed
+var errUsage = errors.New("usage")
+
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {"list [-sort field] [-reverse] [-filter expr]", runList},
+	"add":     {"add [-dir dir] [-paused] file|url|magnet...", runAdd},
+	"start":   {"start id...|all", runStart},
+	"stop":    {"stop id...|all", runStop},
+	"verify":  {"verify id...|all", runVerify},
+	"remove":  {"remove [-delete] id...", runRemove},
+	"move":    {"move [-find] id... dir", runMove},
+	"stats":   {"stats", runStats},
+	"session": {"session get [key...] | session set key=value...", runSession},
+	"tui":     {"tui [-interval duration]", runTUI},
+}
+
+var commandOrder = []string{"list", "add", "start", "stop", "verify", "remove", "move", "stats", "session", "tui"}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "transmission-cli:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer, getenv func(string) string) error {
+	cfg := defaultConfig()
+
+	fs := flag.NewFlagSet("transmission-cli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "config file (default $"+envConfig+" or "+defaultConfigPath()+")")
+	url := fs.String("url", "", "RPC endpoint (default "+cfg.URL+")")
+	username := fs.String("username", "", "RPC username")
+	password := fs.String("password", "", "RPC password")
+	output := fs.String("o", "", "output format: table, json or csv")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: transmission-cli [flags] command [arguments]\n\nCommands:")
+		for _, name := range commandOrder {
+			fmt.Fprintln(stderr, "  "+commands[name].usage)
+		}
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	path, required := *configPath, true
+	if path == "" {
+		path = getenv(envConfig)
+	}
+	if path == "" {
+		path, required = defaultConfigPath(), false
+	}
+	if path != "" {
+		if err := cfg.loadFile(path, required); err != nil {
+			return err
+		}
+	}
+	cfg.loadEnv(getenv)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "url":
+			cfg.URL = *url
+		case "username":
+			cfg.Username = *username
+		case "password":
+			cfg.Password = *password
+		case "o":
+			cfg.Output = *output
+		}
+	})
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "transmission-cli: unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := cfg.client()
+	if err != nil {
+		return err
+	}
+	e := &env{client: client, out: stdout, errOut: stderr, format: cfg.Output}
+	err = cmd.run(e, fs.Args()[1:])
+	if err == errUsage {
+		fmt.Fprintln(stderr, "usage: transmission-cli "+cmd.usage)
+	}
+	return err
+}
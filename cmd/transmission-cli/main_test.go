@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/tubbebubbe/transmission"
+)
+
+type rpcRequest struct {
+	Method    string                 `json:"method"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// fakeDaemon answers each method with the arguments in replies and
+// records the requests
+func fakeDaemon(replies map[string]string) (*httptest.Server, func() []rpcRequest) {
+	var (
+		mu       sync.Mutex
+		requests []rpcRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var r rpcRequest
+		json.NewDecoder(req.Body).Decode(&r)
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+
+		reply, ok := replies[r.Method]
+		if !ok {
+			reply = "{}"
+		}
+		fmt.Fprintf(res, `{"arguments":%s,"result":"success"}`, reply)
+	}))
+	return server, func() []rpcRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]rpcRequest(nil), requests...)
+	}
+}
+
+// methods returns the methods of requests, leaving out session-get
+func methods(requests []rpcRequest) []rpcRequest {
+	var out []rpcRequest
+	for _, r := range requests {
+		if r.Method != "session-get" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func noEnv(string) string { return "" }
+
+const torrentsReply = `{"torrents":[
+	{"id":1,"name":"Sintel","status":6,"percentDone":1,"uploadRatio":2.5,"sizeWhenDone":1048576},
+	{"id":2,"name":"Big Buck Bunny","status":4,"percentDone":0.5,"uploadRatio":0.1,"sizeWhenDone":2048},
+	{"id":3,"name":"Tears of Steel","status":0,"percentDone":0,"uploadRatio":-1,"sizeWhenDone":10}
+]}`
+
+func TestConfig(t *testing.T) {
+	Convey("Test config file, environment and flags override each other", t, func() {
+		dir, _ := ioutil.TempDir("", "transmission-cli")
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "config")
+		ioutil.WriteFile(path, []byte("# comment\nurl = http://file/rpc\nusername = file\npassword = secret\n"), 0600)
+
+		cfg := defaultConfig()
+		So(cfg.loadFile(path, true), ShouldBeNil)
+		So(cfg.URL, ShouldEqual, "http://file/rpc")
+
+		cfg.loadEnv(func(k string) string {
+			if k == envUsername {
+				return "env"
+			}
+			return ""
+		})
+		So(cfg.Username, ShouldEqual, "env")
+		So(cfg.Password, ShouldEqual, "secret")
+
+		So(cfg.loadFile(filepath.Join(dir, "missing"), false), ShouldBeNil)
+		So(cfg.loadFile(filepath.Join(dir, "missing"), true), ShouldNotBeNil)
+
+		ioutil.WriteFile(path, []byte("color = blue\n"), 0600)
+		So(cfg.loadFile(path, true), ShouldNotBeNil)
+	})
+}
+
+func TestList(t *testing.T) {
+	Convey("Test listing sorted and filtered torrents as csv", t, func() {
+		server, _ := fakeDaemon(map[string]string{"torrent-get": torrentsReply})
+		defer server.Close()
+
+		var out bytes.Buffer
+		err := run([]string{"-url", server.URL, "-o", "csv", "list", "-sort", "ratio", "-reverse", "-filter", "-status:stopped"}, &out, ioutil.Discard, noEnv)
+		So(err, ShouldBeNil)
+		So(out.String(), ShouldEqual, "ID,NAME,STATUS,DONE,SIZE,DOWN,UP,RATIO,ETA\n"+
+			"1,Sintel,Seeding,100.0%,1.0 MiB,0 B/s,0 B/s,2.50,0s\n"+
+			"2,Big Buck Bunny,Downloading,50.0%,2.0 KiB,0 B/s,0 B/s,0.10,0s\n")
+	})
+
+	Convey("Test listing as json", t, func() {
+		server, _ := fakeDaemon(map[string]string{"torrent-get": torrentsReply})
+		defer server.Close()
+
+		var out bytes.Buffer
+		So(run([]string{"-url", server.URL, "-o", "json", "list"}, &out, ioutil.Discard, noEnv), ShouldBeNil)
+		var torrents transmission.Torrents
+		So(json.Unmarshal(out.Bytes(), &torrents), ShouldBeNil)
+		So(torrents, ShouldHaveLength, 3)
+	})
+
+	Convey("Test a bad filter is reported", t, func() {
+		server, _ := fakeDaemon(nil)
+		defer server.Close()
+		err := run([]string{"-url", server.URL, "list", "-filter", "ratio>>1"}, ioutil.Discard, ioutil.Discard, noEnv)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestCommands(t *testing.T) {
+	Convey("Test adding a magnet link paused", t, func() {
+		server, requests := fakeDaemon(map[string]string{
+			"torrent-add": `{"torrent-added":{"id":9,"name":"Sintel","hashString":"abc"}}`,
+		})
+		defer server.Close()
+
+		var out bytes.Buffer
+		err := run([]string{"-url", server.URL, "add", "-paused", "-dir", "/data", "magnet:?xt=urn:btih:abc"}, &out, ioutil.Discard, noEnv)
+		So(err, ShouldBeNil)
+		So(out.String(), ShouldContainSubstring, "9   Sintel  abc")
+
+		reqs := methods(requests())
+		So(reqs, ShouldHaveLength, 1)
+		So(reqs[0].Arguments["filename"], ShouldEqual, "magnet:?xt=urn:btih:abc")
+		So(reqs[0].Arguments["paused"], ShouldEqual, true)
+		So(reqs[0].Arguments["download-dir"], ShouldEqual, "/data")
+	})
+
+	Convey("Test adding a torrent the daemon already has", t, func() {
+		server, _ := fakeDaemon(map[string]string{
+			"torrent-add": `{"torrent-duplicate":{"id":4,"name":"Sintel","hashString":"abc"}}`,
+		})
+		defer server.Close()
+
+		var out, errOut bytes.Buffer
+		err := run([]string{"-url", server.URL, "add", "magnet:?xt=urn:btih:abc"}, &out, &errOut, noEnv)
+		So(err, ShouldBeNil)
+		So(out.String(), ShouldContainSubstring, "4   Sintel  abc")
+		So(errOut.String(), ShouldContainSubstring, "already added")
+	})
+
+	Convey("Test moving torrents", t, func() {
+		server, requests := fakeDaemon(nil)
+		defer server.Close()
+
+		So(run([]string{"-url", server.URL, "move", "1", "2", "/new"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldBeNil)
+		reqs := methods(requests())
+		So(reqs, ShouldHaveLength, 2)
+		So(reqs[1].Method, ShouldEqual, "torrent-set-location")
+		So(reqs[1].Arguments["location"], ShouldEqual, "/new")
+		So(reqs[1].Arguments["move"], ShouldEqual, true)
+
+		So(run([]string{"-url", server.URL, "move", "-find", "1", "/old"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldBeNil)
+		reqs = methods(requests())
+		So(reqs[len(reqs)-1].Arguments["move"], ShouldEqual, false)
+	})
+
+	Convey("Test bad ids and unknown commands", t, func() {
+		server, _ := fakeDaemon(nil)
+		defer server.Close()
+
+		So(run([]string{"-url", server.URL, "start", "one"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldNotBeNil)
+		So(run([]string{"-url", server.URL, "frobnicate"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldEqual, errUsage)
+		So(run([]string{"-url", server.URL, "remove"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldEqual, errUsage)
+		So(run([]string{"-url", server.URL, "tui", "-interval", "0"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldEqual, errUsage)
+		So(run([]string{"-url", server.URL, "tui", "-interval", "-1s"}, ioutil.Discard, ioutil.Discard, noEnv), ShouldEqual, errUsage)
+	})
+}
+
+func TestSession(t *testing.T) {
+	Convey("Test getting session keys", t, func() {
+		server, _ := fakeDaemon(map[string]string{
+			"session-get": `{"rpc-version":17,"download-dir":"/data","speed-limit-down":1000000}`,
+		})
+		defer server.Close()
+
+		var out bytes.Buffer
+		err := run([]string{"-url", server.URL, "session", "get", "download-dir", "speed-limit-down"}, &out, ioutil.Discard, noEnv)
+		So(err, ShouldBeNil)
+		So(out.String(), ShouldEqual, "KEY               VALUE\ndownload-dir      /data\nspeed-limit-down  1000000\n")
+	})
+
+	Convey("Test setting session keys", t, func() {
+		server, requests := fakeDaemon(map[string]string{"session-get": `{"rpc-version":17}`})
+		defer server.Close()
+
+		err := run([]string{"-url", server.URL, "session", "set", "speed-limit-down=500", "speed-limit-down-enabled=true", "download-dir=/data"}, ioutil.Discard, ioutil.Discard, noEnv)
+		So(err, ShouldBeNil)
+		reqs := methods(requests())
+		So(reqs, ShouldHaveLength, 1)
+		So(reqs[0].Arguments, ShouldResemble, map[string]interface{}{
+			"speed-limit-down":         500.0,
+			"speed-limit-down-enabled": true,
+			"download-dir":             "/data",
+		})
+
+		var settings transmission.SessionSettings
+		So(setSetting(&settings, "speed-limit-up", "fast"), ShouldNotBeNil)
+		So(setSetting(&settings, "no-such-key", "1"), ShouldNotBeNil)
+		So(setSetting(&settings, "encryption", "sometimes"), ShouldNotBeNil)
+		So(setSetting(&settings, "encryption", "required"), ShouldBeNil)
+		So(*settings.Encryption, ShouldEqual, transmission.EncryptionRequired)
+	})
+}
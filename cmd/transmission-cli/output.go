@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tubbebubbe/transmission"
+	"github.com/tubbebubbe/transmission/format"
+)
+
+// table is what commands print. Rows are used by the table and csv
+// formats, Value by the json format.
+type table struct {
+	Header []string
+	Rows   [][]string
+	Value  interface{}
+}
+
+func (t *table) add(row ...string) {
+	t.Rows = append(t.Rows, row)
+}
+
+func (t *table) write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		if len(t.Header) > 0 {
+			fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+		}
+		for _, row := range t.Rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		if len(t.Header) > 0 {
+			cw.Write(t.Header)
+		}
+		cw.WriteAll(t.Rows)
+		return cw.Error()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t.Value)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func torrentTable(torrents transmission.Torrents) *table {
+	t := &table{
+		Header: []string{"ID", "NAME", "STATUS", "DONE", "SIZE", "DOWN", "UP", "RATIO", "ETA"},
+		Value:  torrents,
+	}
+	for _, tr := range torrents {
+		t.add(
+			fmt.Sprint(tr.ID),
+			tr.Name,
+			tr.TorrentStatus(),
+			format.Percent(tr.PercentDone),
+			format.Size(tr.SizeWhenDone),
+			format.Speed(tr.RateDownload),
+			format.Speed(tr.RateUpload),
+			format.Ratio(tr.UploadRatio),
+			format.ETA(tr.Eta),
+		)
+	}
+	return t
+}
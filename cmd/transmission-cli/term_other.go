@@ -0,0 +1,28 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+type terminal struct {
+	in, out *os.File
+}
+
+func openTerminal() (*terminal, error) {
+	return nil, errors.New("the terminal UI isn't supported on this platform")
+}
+
+func (t *terminal) restore() error {
+	return nil
+}
+
+func (t *terminal) size() (int, int, error) {
+	return 80, 24, nil
+}
+
+func (t *terminal) notifyResize() <-chan os.Signal {
+	return nil
+}
@@ -0,0 +1,67 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// terminal is the controlling terminal put into raw mode
+type terminal struct {
+	in, out *os.File
+	saved   syscall.Termios
+}
+
+func openTerminal() (*terminal, error) {
+	t := &terminal{in: os.Stdin, out: os.Stdout}
+	if err := ioctl(t.in.Fd(), ioctlGetTermios, unsafe.Pointer(&t.saved)); err != nil {
+		return nil, err
+	}
+
+	raw := t.saved
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(t.in.Fd(), ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// restore puts the terminal back the way openTerminal found it
+func (t *terminal) restore() error {
+	return ioctl(t.in.Fd(), ioctlSetTermios, unsafe.Pointer(&t.saved))
+}
+
+// size returns the terminal's width and height
+func (t *terminal) size() (int, int, error) {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	if err := ioctl(t.out.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// notifyResize returns a channel receiving a value when the terminal is resized
+func (t *terminal) notifyResize() <-chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	return ch
+}
+
+func ioctl(fd, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,625 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tubbebubbe/transmission"
+	"github.com/tubbebubbe/transmission/format"
+)
+
+// tuiClient is the part of the client the terminal UI uses
+type tuiClient interface {
+	GetTorrents() (transmission.Torrents, error)
+	GetTorrentDetails(id int) (*transmission.Torrent, error)
+	StartTorrent(id int) (string, error)
+	StopTorrent(id int) (string, error)
+	VerifyTorrent(id int) (string, error)
+	DeleteTorrent(id int, wd bool) (string, error)
+}
+
+// sortOrder is the order the s key cycles through sortings
+var sortOrder = []string{"id", "name", "age", "size", "progress", "down", "up", "downloaded", "uploaded", "ratio"}
+
+// Tabs of the details pane
+const (
+	tabFiles = iota
+	tabPeers
+	tabTrackers
+	numTabs
+)
+
+var tabNames = []string{"Files", "Peers", "Trackers"}
+
+// Input modes
+const (
+	modeNormal = iota
+	modeFilter
+	modeConfirm
+)
+
+const helpLine = "q quit  j/k move  s sort  r reverse  / filter  enter details  tab pane  S start  p stop  v verify  x remove"
+
+// tui is the state of the terminal UI
+type tui struct {
+	client tuiClient
+
+	width, height int
+
+	torrents transmission.Torrents // as last fetched
+	view     transmission.Torrents // torrents filtered and sorted
+	selected int                   // id of the torrent under the cursor
+	cursor   int
+	offset   int // first row shown
+
+	sort    int // index into sortOrder
+	reverse bool
+	expr    string
+	filter  transmission.Predicate
+
+	details *transmission.Torrent // nil when the details pane is closed
+	tab     int
+
+	mode    int
+	input   string
+	message string
+	quit    bool
+}
+
+func newTUI(client tuiClient) *tui {
+	return &tui{client: client, width: 80, height: 24}
+}
+
+// setTorrents replaces the torrents, keeping the cursor on the same torrent
+func (t *tui) setTorrents(torrents transmission.Torrents) {
+	t.torrents = torrents
+	t.update()
+}
+
+// update rebuilds the view after the torrents, sorting or filter changed
+func (t *tui) update() {
+	view := t.torrents
+	if t.filter != nil {
+		view = view.Filter(t.filter)
+	} else {
+		view = append(transmission.Torrents(nil), view...)
+	}
+	sorting := sortings[sortOrder[t.sort]][0]
+	if t.reverse {
+		sorting = sortings[sortOrder[t.sort]][1]
+	}
+	view.SortBy(sorting.Key())
+	t.view = view
+
+	t.cursor = 0
+	for i, tr := range view {
+		if tr.ID == t.selected {
+			t.cursor = i
+			break
+		}
+	}
+	t.moveTo(t.cursor)
+}
+
+// moveTo puts the cursor on row i, scrolling if needed
+func (t *tui) moveTo(i int) {
+	if i >= len(t.view) {
+		i = len(t.view) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	t.cursor = i
+	if len(t.view) > 0 {
+		t.selected = t.view[i].ID
+	}
+
+	rows := t.tableRows()
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+rows {
+		t.offset = t.cursor - rows + 1
+	}
+	if t.offset < 0 {
+		t.offset = 0
+	}
+}
+
+func (t *tui) current() *transmission.Torrent {
+	if t.cursor < len(t.view) {
+		return t.view[t.cursor]
+	}
+	return nil
+}
+
+// tableRows is how many torrents fit on screen
+func (t *tui) tableRows() int {
+	rows := t.height - 3 // title, column header and status line
+	if t.details != nil {
+		rows -= t.detailsRows() + 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+func (t *tui) detailsRows() int {
+	return t.height / 2
+}
+
+// refresh fetches the torrents and, with the details pane open, the
+// details of the selected torrent
+func (t *tui) refresh() {
+	torrents, err := t.client.GetTorrents()
+	if err != nil {
+		t.message = err.Error()
+		return
+	}
+	t.setTorrents(torrents)
+	t.refreshDetails()
+}
+
+func (t *tui) refreshDetails() {
+	if t.details == nil {
+		return
+	}
+	tr := t.current()
+	if tr == nil {
+		t.details = nil
+		return
+	}
+	details, err := t.client.GetTorrentDetails(tr.ID)
+	if err != nil {
+		t.message = err.Error()
+		return
+	}
+	t.details = details
+}
+
+// handleKey acts on a key and reports whether the torrents should be refreshed
+func (t *tui) handleKey(key string) bool {
+	switch t.mode {
+	case modeFilter:
+		return t.filterKey(key)
+	case modeConfirm:
+		return t.confirmKey(key)
+	}
+
+	t.message = ""
+	switch key {
+	case "q", "ctrl-c":
+		t.quit = true
+	case "j", "down":
+		t.moveTo(t.cursor + 1)
+		t.refreshDetails()
+	case "k", "up":
+		t.moveTo(t.cursor - 1)
+		t.refreshDetails()
+	case "pgdn":
+		t.moveTo(t.cursor + t.tableRows())
+		t.refreshDetails()
+	case "pgup":
+		t.moveTo(t.cursor - t.tableRows())
+		t.refreshDetails()
+	case "g", "home":
+		t.moveTo(0)
+		t.refreshDetails()
+	case "G", "end":
+		t.moveTo(len(t.view) - 1)
+		t.refreshDetails()
+	case "s":
+		t.sort = (t.sort + 1) % len(sortOrder)
+		t.update()
+	case "r":
+		t.reverse = !t.reverse
+		t.update()
+	case "/":
+		t.mode = modeFilter
+		t.input = t.expr
+	case "enter":
+		if t.details != nil {
+			t.details = nil
+			t.moveTo(t.cursor)
+			return false
+		}
+		if tr := t.current(); tr != nil {
+			t.details = tr
+			t.moveTo(t.cursor)
+			t.refreshDetails()
+		}
+	case "tab":
+		t.tab = (t.tab + 1) % numTabs
+	case "S":
+		return t.act("Started", t.client.StartTorrent)
+	case "p":
+		return t.act("Stopped", t.client.StopTorrent)
+	case "v":
+		return t.act("Verifying", t.client.VerifyTorrent)
+	case "x":
+		if t.current() != nil {
+			t.mode = modeConfirm
+		}
+	}
+	return false
+}
+
+// act calls fn on the selected torrent
+func (t *tui) act(done string, fn func(int) (string, error)) bool {
+	tr := t.current()
+	if tr == nil {
+		return false
+	}
+	if _, err := fn(tr.ID); err != nil {
+		t.message = err.Error()
+		return false
+	}
+	t.message = done + " " + tr.Name
+	return true
+}
+
+func (t *tui) filterKey(key string) bool {
+	switch key {
+	case "esc", "ctrl-c":
+		t.mode = modeNormal
+	case "enter":
+		t.mode = modeNormal
+		if strings.TrimSpace(t.input) == "" {
+			t.expr, t.filter = "", nil
+			t.update()
+			return false
+		}
+		pred, err := transmission.ParseFilter(t.input)
+		if err != nil {
+			t.message = err.Error()
+			return false
+		}
+		t.expr, t.filter = t.input, pred
+		t.update()
+	case "backspace":
+		if _, size := utf8.DecodeLastRuneInString(t.input); size > 0 {
+			t.input = t.input[:len(t.input)-size]
+		}
+	default:
+		if utf8.RuneCountInString(key) == 1 {
+			t.input += key
+		}
+	}
+	return false
+}
+
+func (t *tui) confirmKey(key string) bool {
+	t.mode = modeNormal
+	switch key {
+	case "y", "d":
+		deleteData := key == "d"
+		return t.act("Removed", func(id int) (string, error) {
+			return t.client.DeleteTorrent(id, deleteData)
+		})
+	}
+	return false
+}
+
+// Column widths of the torrent table, the name gets what's left
+var columns = []struct {
+	title string
+	width int
+}{
+	{"ID", 5}, {"Name", 0}, {"Status", 16}, {"Done", 7}, {"Size", 10},
+	{"Down", 12}, {"Up", 12}, {"Ratio", 7}, {"ETA", 10},
+}
+
+// render draws the whole screen
+func (t *tui) render(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H")
+	line := func(style, s string) {
+		if style != "" {
+			buf.WriteString(style)
+		}
+		buf.WriteString(fit(s, t.width))
+		if style != "" {
+			buf.WriteString("\x1b[0m")
+		}
+		buf.WriteString("\x1b[K\r\n")
+	}
+
+	line("\x1b[7m", t.title())
+	line("\x1b[1m", t.row(func(i int) string { return columns[i].title }))
+
+	rows := t.tableRows()
+	for i := t.offset; i < t.offset+rows; i++ {
+		if i >= len(t.view) {
+			line("", "")
+			continue
+		}
+		style := ""
+		if i == t.cursor {
+			style = "\x1b[7m"
+		}
+		line(style, t.row(torrentColumns(t.view[i])))
+	}
+
+	if t.details != nil {
+		line("\x1b[1m", t.detailsTitle())
+		lines := t.detailsLines()
+		for i := 0; i < t.detailsRows(); i++ {
+			if i < len(lines) {
+				line("", lines[i])
+			} else {
+				line("", "")
+			}
+		}
+	}
+
+	buf.WriteString(fit(t.statusLine(), t.width))
+	buf.WriteString("\x1b[K\x1b[J")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (t *tui) title() string {
+	var down, up uint64
+	for _, tr := range t.torrents {
+		down += tr.RateDownload
+		up += tr.RateUpload
+	}
+	order := "asc"
+	if t.reverse {
+		order = "desc"
+	}
+	title := fmt.Sprintf(" transmission  %d/%d torrents  down %s  up %s  sort: %s %s",
+		len(t.view), len(t.torrents), format.Speed(down), format.Speed(up), sortOrder[t.sort], order)
+	if t.expr != "" {
+		title += "  filter: " + t.expr
+	}
+	return title
+}
+
+func (t *tui) statusLine() string {
+	switch t.mode {
+	case modeFilter:
+		return "Filter: " + t.input + "_"
+	case modeConfirm:
+		if tr := t.current(); tr != nil {
+			return fmt.Sprintf("Remove %s? y = remove, d = remove and delete data, any other key = cancel", tr.Name)
+		}
+	}
+	if t.message != "" {
+		return t.message
+	}
+	return helpLine
+}
+
+// row lays out the columns returned by col
+func (t *tui) row(col func(int) string) string {
+	nameWidth := t.width
+	for _, c := range columns {
+		nameWidth -= c.width + 1
+	}
+	if nameWidth < 10 {
+		nameWidth = 10
+	}
+
+	parts := make([]string, len(columns))
+	for i, c := range columns {
+		width := c.width
+		if width == 0 {
+			width = nameWidth
+		}
+		parts[i] = fit(col(i), width)
+	}
+	return strings.Join(parts, " ")
+}
+
+func torrentColumns(tr *transmission.Torrent) func(int) string {
+	values := []string{
+		fmt.Sprint(tr.ID),
+		tr.Name,
+		tr.TorrentStatus(),
+		format.Percent(tr.PercentDone),
+		format.Size(tr.SizeWhenDone),
+		format.Speed(tr.RateDownload),
+		format.Speed(tr.RateUpload),
+		format.Ratio(tr.UploadRatio),
+		format.ETA(tr.Eta),
+	}
+	return func(i int) string { return values[i] }
+}
+
+func (t *tui) detailsTitle() string {
+	var tabs []string
+	for i, name := range tabNames {
+		if i == t.tab {
+			name = "[" + name + "]"
+		}
+		tabs = append(tabs, name)
+	}
+	return fmt.Sprintf("%s  %s", t.details.Name, strings.Join(tabs, " "))
+}
+
+func (t *tui) detailsLines() []string {
+	d := t.details
+	var lines []string
+	switch t.tab {
+	case tabFiles:
+		for i, f := range d.Files {
+			wanted := "yes"
+			if i < len(d.FileStats) && !d.FileStats[i].Wanted {
+				wanted = "no"
+			}
+			done := 0.0
+			if f.Length > 0 {
+				done = float64(f.BytesCompleted) / float64(f.Length)
+			}
+			lines = append(lines, fmt.Sprintf("%s %6s  %10s  %-3s  %s",
+				format.ProgressBar(done, 10), format.Percent(done), format.Size(f.Length), wanted, f.Name))
+		}
+	case tabPeers:
+		for _, p := range d.Peers {
+			lines = append(lines, fmt.Sprintf("%-40s  %6s  down %12s  up %12s  %-6s  %s",
+				fmt.Sprintf("%s:%d", p.Address, p.Port), format.Percent(p.Progress),
+				format.Speed(p.RateToClient), format.Speed(p.RateToPeer), p.FlagStr, p.ClientName))
+		}
+	case tabTrackers:
+		for _, tr := range d.TrackerStats {
+			lines = append(lines, fmt.Sprintf("tier %d  %-30s  seeders %d  leechers %d  %s",
+				tr.Tier, tr.Host, tr.SeederCount, tr.LeecherCount, tr.LastAnnounceResult))
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  (none)")
+	}
+	return lines
+}
+
+// fit pads or truncates s to width runes
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		if width < 1 {
+			return ""
+		}
+		return string([]rune(s)[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// parseKeys splits terminal input into key names: "up", "down", "pgup",
+// "pgdn", "home", "end", "enter", "tab", "esc", "backspace", "ctrl-c" or
+// the typed character
+func parseKeys(b []byte) []string {
+	var keys []string
+	for len(b) > 0 {
+		switch b[0] {
+		case 0x1b:
+			key, n := parseEscape(b)
+			keys = append(keys, key)
+			b = b[n:]
+			continue
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case 0x7f, 0x08:
+			keys = append(keys, "backspace")
+		default:
+			r, n := utf8.DecodeRune(b)
+			keys = append(keys, string(r))
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// parseEscape parses the escape sequence b starts with, returning the key
+// and its length. Unknown sequences are skipped.
+func parseEscape(b []byte) (string, int) {
+	if len(b) < 3 || (b[1] != '[' && b[1] != 'O') {
+		return "esc", 1
+	}
+	i := 2
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	if i == len(b) {
+		return "", len(b)
+	}
+	n := i + 1
+	switch string(b[2:n]) {
+	case "A":
+		return "up", n
+	case "B":
+		return "down", n
+	case "H", "1~", "7~":
+		return "home", n
+	case "F", "4~", "8~":
+		return "end", n
+	case "5~":
+		return "pgup", n
+	case "6~":
+		return "pgdn", n
+	}
+	return "", n
+}
+
+func runTUI(e *env, args []string) error {
+	fs := e.flags("tui")
+	interval := fs.Duration("interval", 2*time.Second, "how often to refresh")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return errUsage
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(e.errOut, "transmission-cli: -interval must be positive")
+		return errUsage
+	}
+
+	term, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer term.restore()
+	fmt.Fprint(term.out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(term.out, "\x1b[?25h\x1b[?1049l")
+
+	t := newTUI(e.client)
+	resize := func() {
+		if w, h, err := term.size(); err == nil && w > 0 && h > 0 {
+			t.width, t.height = w, h
+			t.moveTo(t.cursor)
+		}
+	}
+	resize()
+
+	keys := make(chan []string)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := term.in.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- parseKeys(buf[:n])
+		}
+	}()
+	resized := term.notifyResize()
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	t.refresh()
+	for !t.quit {
+		if err := t.render(term.out); err != nil {
+			return err
+		}
+		select {
+		case batch, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			refresh := false
+			for _, key := range batch {
+				if t.handleKey(key) {
+					refresh = true
+				}
+			}
+			if refresh {
+				t.refresh()
+			}
+		case <-resized:
+			resize()
+		case <-ticker.C:
+			t.refresh()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/tubbebubbe/transmission"
+)
+
+type fakeClient struct {
+	torrents transmission.Torrents
+	calls    []string
+}
+
+func (c *fakeClient) GetTorrents() (transmission.Torrents, error) {
+	return append(transmission.Torrents(nil), c.torrents...), nil
+}
+
+func (c *fakeClient) GetTorrentDetails(id int) (*transmission.Torrent, error) {
+	for _, t := range c.torrents {
+		if t.ID == id {
+			d := *t
+			d.Files = []transmission.TorrentFile{{Name: t.Name + ".mkv", Length: 100, BytesCompleted: 50}}
+			d.Peers = []transmission.Peer{{Address: "10.0.0.2", Port: 51413, ClientName: "qBittorrent"}}
+			return &d, nil
+		}
+	}
+	return nil, errors.New("No torrent with that id")
+}
+
+func (c *fakeClient) record(method string, id int) (string, error) {
+	c.calls = append(c.calls, fmt.Sprintf("%s %d", method, id))
+	return "success", nil
+}
+
+func (c *fakeClient) StartTorrent(id int) (string, error)  { return c.record("start", id) }
+func (c *fakeClient) StopTorrent(id int) (string, error)   { return c.record("stop", id) }
+func (c *fakeClient) VerifyTorrent(id int) (string, error) { return c.record("verify", id) }
+
+func (c *fakeClient) DeleteTorrent(id int, wd bool) (string, error) {
+	return c.record(fmt.Sprintf("remove(%v)", wd), id)
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{torrents: transmission.Torrents{
+		{ID: 1, Name: "Sintel", Status: transmission.StatusSeeding, UploadRatio: 2.5},
+		{ID: 2, Name: "Big Buck Bunny", Status: transmission.StatusDownloading, UploadRatio: 0.5},
+		{ID: 3, Name: "Tears of Steel", Status: transmission.StatusStopped, UploadRatio: 1},
+	}}
+}
+
+func viewIDs(t *tui) []int {
+	return t.view.GetIDs()
+}
+
+func press(t *tui, keys ...string) {
+	for _, key := range keys {
+		if t.handleKey(key) {
+			t.refresh()
+		}
+	}
+}
+
+func TestTUI(t *testing.T) {
+	Convey("Test sorting keeps the cursor on the selected torrent", t, func() {
+		ui := newTUI(newFakeClient())
+		ui.refresh()
+		So(viewIDs(ui), ShouldResemble, []int{1, 2, 3})
+
+		press(ui, "j")
+		So(ui.current().ID, ShouldEqual, 2)
+
+		press(ui, "s")
+		So(viewIDs(ui), ShouldResemble, []int{2, 1, 3})
+		So(ui.current().ID, ShouldEqual, 2)
+
+		press(ui, "r")
+		So(viewIDs(ui), ShouldResemble, []int{3, 1, 2})
+		So(ui.current().ID, ShouldEqual, 2)
+	})
+
+	Convey("Test filtering", t, func() {
+		ui := newTUI(newFakeClient())
+		ui.refresh()
+
+		press(ui, "/", "r", "a", "t", "i", "o", "x", "backspace", ">", "=", "1", "enter")
+		So(ui.expr, ShouldEqual, "ratio>=1")
+		So(viewIDs(ui), ShouldResemble, []int{1, 3})
+
+		press(ui, "/", "(", "enter")
+		So(ui.message, ShouldNotBeEmpty)
+		So(ui.expr, ShouldEqual, "ratio>=1")
+
+		press(ui, "/")
+		So(ui.input, ShouldEqual, "ratio>=1")
+		ui.input = ""
+		press(ui, "enter")
+		So(viewIDs(ui), ShouldResemble, []int{1, 2, 3})
+	})
+
+	Convey("Test actions and removal", t, func() {
+		client := newFakeClient()
+		ui := newTUI(client)
+		ui.refresh()
+
+		press(ui, "S", "j", "p", "v", "x", "n", "x", "d")
+		So(client.calls, ShouldResemble, []string{"start 1", "stop 2", "verify 2", "remove(true) 2"})
+		So(ui.message, ShouldEqual, "Removed Big Buck Bunny")
+	})
+
+	Convey("Test the details pane", t, func() {
+		ui := newTUI(newFakeClient())
+		ui.refresh()
+		press(ui, "G", "enter")
+		So(ui.details.Files[0].Name, ShouldEqual, "Tears of Steel.mkv")
+
+		var out bytes.Buffer
+		So(ui.render(&out), ShouldBeNil)
+		So(out.String(), ShouldContainSubstring, "[Files]")
+		So(out.String(), ShouldContainSubstring, "Tears of Steel.mkv")
+
+		press(ui, "tab")
+		out.Reset()
+		ui.render(&out)
+		So(out.String(), ShouldContainSubstring, "10.0.0.2:51413")
+
+		press(ui, "k")
+		So(ui.details.ID, ShouldEqual, 2)
+		press(ui, "enter")
+		So(ui.details, ShouldBeNil)
+	})
+
+	Convey("Test rendering fits the screen", t, func() {
+		ui := newTUI(newFakeClient())
+		ui.width, ui.height = 60, 10
+		ui.refresh()
+
+		var out bytes.Buffer
+		So(ui.render(&out), ShouldBeNil)
+		So(out.String(), ShouldContainSubstring, "3/3 torrents")
+		So(out.String(), ShouldContainSubstring, "Seeding")
+		So(bytes.Count(out.Bytes(), []byte("\r\n")), ShouldEqual, ui.height-1)
+	})
+
+	Convey("Test parsing keys", t, func() {
+		So(parseKeys([]byte("j\x1b[A\x1b[6~\r\x7fé\x1b")), ShouldResemble,
+			[]string{"j", "up", "pgdn", "enter", "backspace", "é", "esc"})
+		So(fit("Big Buck Bunny", 5), ShouldEqual, "Big …")
+		So(fit("Sintel", 8), ShouldEqual, "Sintel  ")
+	})
+}
@@ -0,0 +1,144 @@
+package transmission
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CredentialProvider supplies the username and password used to
+// authenticate against the daemon. It is asked on every request, so
+// credentials can be rotated without rebuilding the client.
+type CredentialProvider interface {
+	Credentials() (username, password string, err error)
+}
+
+// CredentialsFunc adapts a function, e.g. a secrets store lookup, to a CredentialProvider
+type CredentialsFunc func() (username, password string, err error)
+
+// Credentials calls f
+func (f CredentialsFunc) Credentials() (string, string, error) {
+	return f()
+}
+
+type staticCredentials struct {
+	username string
+	password string
+}
+
+func (c staticCredentials) Credentials() (string, string, error) {
+	return c.username, c.password, nil
+}
+
+// StaticCredentials always returns the given username and password
+func StaticCredentials(username, password string) CredentialProvider {
+	return staticCredentials{username: username, password: password}
+}
+
+// EnvCredentials reads the username and password from the given
+// environment variables each time they are needed
+func EnvCredentials(usernameVar, passwordVar string) CredentialProvider {
+	return CredentialsFunc(func() (string, string, error) {
+		return os.Getenv(usernameVar), os.Getenv(passwordVar), nil
+	})
+}
+
+// NetrcCredentials reads the login and password for machine from a netrc
+// file each time they are needed. An empty path uses $NETRC, falling back
+// to ~/.netrc. The "default" entry is used when no machine matches.
+func NetrcCredentials(path, machine string) CredentialProvider {
+	return CredentialsFunc(func() (string, string, error) {
+		file := path
+		if file == "" {
+			file = os.Getenv("NETRC")
+		}
+		if file == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", err
+			}
+			file = filepath.Join(home, ".netrc")
+		}
+		return readNetrc(file, machine)
+	})
+}
+
+func readNetrc(path, machine string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	type entry struct{ login, password string }
+	var (
+		found, fallback *entry
+		current         *entry
+		inMacro         bool
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inMacro {
+			// macro definitions run until the next blank line
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			next := func() string {
+				if i+1 < len(fields) {
+					i++
+					return fields[i]
+				}
+				return ""
+			}
+
+			switch fields[i] {
+			case "machine":
+				current = nil
+				if name := next(); name == machine && found == nil {
+					found = &entry{}
+					current = found
+				}
+			case "default":
+				current = nil
+				if fallback == nil {
+					fallback = &entry{}
+					current = fallback
+				}
+			case "login":
+				if login := next(); current != nil {
+					current.login = login
+				}
+			case "password":
+				if password := next(); current != nil {
+					current.password = password
+				}
+			case "account":
+				next()
+			case "macdef":
+				next()
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case found != nil:
+		return found.login, found.password, nil
+	case fallback != nil:
+		return fallback.login, fallback.password, nil
+	}
+	return "", "", fmt.Errorf("transmission: no netrc entry for %q in %s", machine, path)
+}
@@ -0,0 +1,121 @@
+package transmission
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNetrcCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "netrc")
+	ioutil.WriteFile(path, []byte(`machine other login nope password nope
+machine seedbox
+  login alice
+  password s3cret
+macdef init
+  machine seedbox login macro password macro
+
+default login anon password guest
+`), 0600)
+
+	Convey("Test reading a machine entry", t, func() {
+		username, password, err := NetrcCredentials(path, "seedbox").Credentials()
+		So(err, ShouldBeNil)
+		So(username, ShouldEqual, "alice")
+		So(password, ShouldEqual, "s3cret")
+	})
+
+	Convey("Test falling back to the default entry", t, func() {
+		username, password, err := NetrcCredentials(path, "unknown").Credentials()
+		So(err, ShouldBeNil)
+		So(username, ShouldEqual, "anon")
+		So(password, ShouldEqual, "guest")
+	})
+
+	Convey("Test $NETRC is read each time", t, func() {
+		other := filepath.Join(dir, "other")
+		ioutil.WriteFile(other, []byte("machine seedbox login bob password hunter2\n"), 0600)
+		defer os.Unsetenv("NETRC")
+
+		creds := NetrcCredentials("", "seedbox")
+		os.Setenv("NETRC", path)
+		username, _, err := creds.Credentials()
+		So(err, ShouldBeNil)
+		So(username, ShouldEqual, "alice")
+
+		os.Setenv("NETRC", other)
+		username, _, err = creds.Credentials()
+		So(err, ShouldBeNil)
+		So(username, ShouldEqual, "bob")
+	})
+
+	Convey("Test a missing file", t, func() {
+		_, _, err := NetrcCredentials(filepath.Join(dir, "missing"), "seedbox").Credentials()
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestEnvCredentials(t *testing.T) {
+	Convey("Test credentials are read from the environment", t, func() {
+		os.Setenv("TRANSMISSION_TEST_USER", "bob")
+		os.Setenv("TRANSMISSION_TEST_PASS", "hunter2")
+		defer os.Unsetenv("TRANSMISSION_TEST_USER")
+		defer os.Unsetenv("TRANSMISSION_TEST_PASS")
+
+		username, password, err := EnvCredentials("TRANSMISSION_TEST_USER", "TRANSMISSION_TEST_PASS").Credentials()
+		So(err, ShouldBeNil)
+		So(username, ShouldEqual, "bob")
+		So(password, ShouldEqual, "hunter2")
+	})
+}
+
+func TestRotateCredentials(t *testing.T) {
+	password := "old"
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if u, p, _ := req.BasicAuth(); u != "test" || p != password {
+			http.Error(res, "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(res, `{"arguments":{},"result":"success"}`)
+	}))
+	defer server.Close()
+
+	Convey("Test credentials can be rotated on a live client", t, func() {
+		c := NewApiClient(server.URL, "test", "old")
+		_, err := c.Post("{}")
+		So(err, ShouldBeNil)
+
+		password = "new"
+		_, err = c.Post("{}")
+		So(errors.Is(err, ErrUnauthorized), ShouldBeTrue)
+
+		c.SetCredentials(CredentialsFunc(func() (string, string, error) {
+			return "test", "new", nil
+		}))
+		_, err = c.Post("{}")
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Test provider errors are returned", t, func() {
+		c := NewApiClient(server.URL, "", "")
+		c.SetCredentials(CredentialsFunc(func() (string, string, error) {
+			return "", "", errors.New("vault sealed")
+		}))
+		_, err := c.Post("{}")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "vault sealed")
+	})
+}
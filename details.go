@@ -0,0 +1,66 @@
+package transmission
+
+import "errors"
+
+// detailFields are the torrent-get fields GetTorrentDetails adds
+var detailFields = []string{"files", "fileStats", "peers", "trackerStats"}
+
+// TorrentFile is one of a torrent's files
+type TorrentFile struct {
+	Name           string `json:"name"`
+	Length         uint64 `json:"length"`
+	BytesCompleted uint64 `json:"bytesCompleted"`
+}
+
+// FileStat holds the download settings of a file, in the order of Files
+type FileStat struct {
+	BytesCompleted uint64   `json:"bytesCompleted"`
+	Wanted         bool     `json:"wanted"`
+	Priority       Priority `json:"priority"`
+}
+
+// Peer is a peer the torrent is connected to
+type Peer struct {
+	Address      string  `json:"address"`
+	Port         int     `json:"port"`
+	ClientName   string  `json:"clientName"`
+	FlagStr      string  `json:"flagStr"`
+	IsEncrypted  bool    `json:"isEncrypted"`
+	IsIncoming   bool    `json:"isIncoming"`
+	Progress     float64 `json:"progress"`
+	RateToClient uint64  `json:"rateToClient"`
+	RateToPeer   uint64  `json:"rateToPeer"`
+}
+
+// TrackerStat is the announce and scrape state of one of the torrent's trackers
+type TrackerStat struct {
+	ID                    int    `json:"id"`
+	Announce              string `json:"announce"`
+	Host                  string `json:"host"`
+	Tier                  int    `json:"tier"`
+	IsBackup              bool   `json:"isBackup"`
+	LastAnnounceResult    string `json:"lastAnnounceResult"`
+	LastAnnounceSucceeded bool   `json:"lastAnnounceSucceeded"`
+	LastAnnounceTime      int64  `json:"lastAnnounceTime"`
+	NextAnnounceTime      int64  `json:"nextAnnounceTime"`
+	SeederCount           int    `json:"seederCount"`
+	LeecherCount          int    `json:"leecherCount"`
+	DownloadCount         int    `json:"downloadCount"`
+}
+
+// GetTorrentDetails is GetTorrent with the torrent's files, peers and
+// tracker stats filled in
+func (ac *TransmissionClient) GetTorrentDetails(id int) (*Torrent, error) {
+	fields, err := ac.torrentFields(detailFields...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Call[TorrentGetRequest, TorrentGetResponse](ac, "torrent-get", TorrentGetRequest{Fields: fields, IDs: []int{id}})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Torrents) == 0 {
+		return nil, errors.New("No torrent with that id")
+	}
+	return out.Torrents[0], nil
+}
@@ -0,0 +1,57 @@
+package transmission
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetTorrentDetails(t *testing.T) {
+	Convey("Test getting files, peers and trackers", t, func() {
+		server, requests := rpcServer(17, map[string]string{
+			"torrent-get": `{"torrents":[{"id":3,"name":"Sintel",
+  "files":[{"name":"Sintel/sintel.mkv","length":1000,"bytesCompleted":250}],
+  "fileStats":[{"bytesCompleted":250,"wanted":true,"priority":1}],
+  "peers":[{"address":"10.0.0.2","port":51413,"clientName":"Transmission 4.0.5","rateToClient":2048}],
+  "trackerStats":[{"id":0,"host":"tracker.example.org","tier":0,"seederCount":12,"lastAnnounceSucceeded":true}]}]}`,
+		})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		torrent, err := client.GetTorrentDetails(3)
+		So(err, ShouldBeNil)
+		So(torrent.Files, ShouldResemble, []TorrentFile{{Name: "Sintel/sintel.mkv", Length: 1000, BytesCompleted: 250}})
+		So(torrent.FileStats[0].Wanted, ShouldBeTrue)
+		So(torrent.Peers[0].ClientName, ShouldEqual, "Transmission 4.0.5")
+		So(torrent.Peers[0].RateToClient, ShouldEqual, uint64(2048))
+		So(torrent.TrackerStats[0].SeederCount, ShouldEqual, 12)
+
+		reqs := requests()
+		So(reqs[0].Arguments["ids"], ShouldResemble, []interface{}{float64(3)})
+		So(reqs[0].Arguments["fields"], ShouldContain, "trackerStats")
+		So(reqs[0].Arguments["fields"], ShouldContain, "name")
+	})
+
+	Convey("Test details over JSON-RPC", t, func() {
+		var last map[string]interface{}
+		server := jsonrpcServer(18, `{"torrents":[{"id":3,"file_stats":[{"bytes_completed":5,"wanted":true}],
+  "tracker_stats":[{"last_announce_result":"Success","seeder_count":4}]}]}`, &last)
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		torrent, err := client.GetTorrentDetails(3)
+		So(err, ShouldBeNil)
+		So(torrent.FileStats[0].BytesCompleted, ShouldEqual, uint64(5))
+		So(torrent.TrackerStats[0].LastAnnounceResult, ShouldEqual, "Success")
+		So(torrent.TrackerStats[0].SeederCount, ShouldEqual, 4)
+	})
+
+	Convey("Test a missing torrent", t, func() {
+		server, _ := rpcServer(17, map[string]string{"torrent-get": `{"torrents":[]}`})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		_, err := client.GetTorrentDetails(3)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,251 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Status is what a torrent is doing
+type Status int
+
+const (
+	StatusStopped Status = iota
+	StatusCheckPending
+	StatusChecking
+	StatusDownloadPending
+	StatusDownloading
+	StatusSeedPending
+	StatusSeeding
+)
+
+var statusNames = map[int]string{
+	int(StatusStopped):         "stopped",
+	int(StatusCheckPending):    "check-wait",
+	int(StatusChecking):        "checking",
+	int(StatusDownloadPending): "download-wait",
+	int(StatusDownloading):     "downloading",
+	int(StatusSeedPending):     "seed-wait",
+	int(StatusSeeding):         "seeding",
+}
+
+// Statuses returns every status, in order
+func Statuses() []Status {
+	return []Status{
+		StatusStopped, StatusCheckPending, StatusChecking, StatusDownloadPending,
+		StatusDownloading, StatusSeedPending, StatusSeeding,
+	}
+}
+
+func (s Status) String() string { return enumString(statusNames, int(s)) }
+
+func (s Status) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *Status) UnmarshalText(text []byte) error {
+	return enumParse(statusNames, "status", text, (*int)(s))
+}
+
+func (s Status) MarshalJSON() ([]byte, error) { return enumJSON(int(s)) }
+
+func (s *Status) UnmarshalJSON(data []byte) error {
+	return enumUnmarshalJSON(statusNames, "status", data, (*int)(s))
+}
+
+// ErrorType tells what kind of error a torrent has
+type ErrorType int
+
+const (
+	ErrorNone           ErrorType = iota
+	ErrorTrackerWarning           // the tracker returned a warning
+	ErrorTrackerError             // the tracker returned an error
+	ErrorLocal                    // e.g. the disk is full or the files are gone
+)
+
+var errorTypeNames = map[int]string{
+	int(ErrorNone):           "none",
+	int(ErrorTrackerWarning): "tracker-warning",
+	int(ErrorTrackerError):   "tracker-error",
+	int(ErrorLocal):          "local-error",
+}
+
+func (e ErrorType) String() string { return enumString(errorTypeNames, int(e)) }
+
+func (e ErrorType) MarshalText() ([]byte, error) { return []byte(e.String()), nil }
+
+func (e *ErrorType) UnmarshalText(text []byte) error {
+	return enumParse(errorTypeNames, "error type", text, (*int)(e))
+}
+
+func (e ErrorType) MarshalJSON() ([]byte, error) { return enumJSON(int(e)) }
+
+func (e *ErrorType) UnmarshalJSON(data []byte) error {
+	return enumUnmarshalJSON(errorTypeNames, "error type", data, (*int)(e))
+}
+
+// Priority of a torrent's files
+type Priority int
+
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+var priorityNames = map[int]string{
+	int(PriorityLow):    "low",
+	int(PriorityNormal): "normal",
+	int(PriorityHigh):   "high",
+}
+
+func (p Priority) String() string { return enumString(priorityNames, int(p)) }
+
+func (p Priority) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	return enumParse(priorityNames, "priority", text, (*int)(p))
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) { return enumJSON(int(p)) }
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	return enumUnmarshalJSON(priorityNames, "priority", data, (*int)(p))
+}
+
+// RatioMode tells which seed ratio limit applies to a torrent
+type RatioMode int
+
+const (
+	RatioGlobal    RatioMode = iota // the session's limit
+	RatioSingle                     // the torrent's own limit
+	RatioUnlimited                  // seed regardless of ratio
+)
+
+var ratioModeNames = map[int]string{
+	int(RatioGlobal):    "global",
+	int(RatioSingle):    "single",
+	int(RatioUnlimited): "unlimited",
+}
+
+func (m RatioMode) String() string { return enumString(ratioModeNames, int(m)) }
+
+func (m RatioMode) MarshalText() ([]byte, error) { return []byte(m.String()), nil }
+
+func (m *RatioMode) UnmarshalText(text []byte) error {
+	return enumParse(ratioModeNames, "ratio mode", text, (*int)(m))
+}
+
+func (m RatioMode) MarshalJSON() ([]byte, error) { return enumJSON(int(m)) }
+
+func (m *RatioMode) UnmarshalJSON(data []byte) error {
+	return enumUnmarshalJSON(ratioModeNames, "ratio mode", data, (*int)(m))
+}
+
+// IdleMode tells which idle seeding limit applies to a torrent
+type IdleMode int
+
+const (
+	IdleGlobal    IdleMode = iota // the session's limit
+	IdleSingle                    // the torrent's own limit
+	IdleUnlimited                 // seed however long it's idle
+)
+
+var idleModeNames = map[int]string{
+	int(IdleGlobal):    "global",
+	int(IdleSingle):    "single",
+	int(IdleUnlimited): "unlimited",
+}
+
+func (m IdleMode) String() string { return enumString(idleModeNames, int(m)) }
+
+func (m IdleMode) MarshalText() ([]byte, error) { return []byte(m.String()), nil }
+
+func (m *IdleMode) UnmarshalText(text []byte) error {
+	return enumParse(idleModeNames, "idle mode", text, (*int)(m))
+}
+
+func (m IdleMode) MarshalJSON() ([]byte, error) { return enumJSON(int(m)) }
+
+func (m *IdleMode) UnmarshalJSON(data []byte) error {
+	return enumUnmarshalJSON(idleModeNames, "idle mode", data, (*int)(m))
+}
+
+// Encryption is the session's peer encryption setting. Unlike the other
+// enums the daemon sends it as a string.
+type Encryption string
+
+const (
+	EncryptionRequired  Encryption = "required"
+	EncryptionPreferred Encryption = "preferred"
+	EncryptionTolerated Encryption = "tolerated"
+)
+
+func (e Encryption) String() string { return string(e) }
+
+// Known reports whether e is one of the values above
+func (e Encryption) Known() bool {
+	switch e {
+	case EncryptionRequired, EncryptionPreferred, EncryptionTolerated:
+		return true
+	}
+	return false
+}
+
+func (e Encryption) MarshalText() ([]byte, error) { return []byte(e), nil }
+
+// UnmarshalText keeps values newer daemons might send as they are, like
+// the numeric enums keep unknown numbers
+func (e *Encryption) UnmarshalText(text []byte) error {
+	*e = Encryption(text)
+	return nil
+}
+
+func (e Encryption) MarshalJSON() ([]byte, error) { return json.Marshal(string(e)) }
+
+func (e *Encryption) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return e.UnmarshalText([]byte(s))
+}
+
+// enumString returns the name of v, or v as a number if it has none
+func enumString(names map[int]string, v int) string {
+	if s, ok := names[v]; ok {
+		return s
+	}
+	return strconv.Itoa(v)
+}
+
+// enumParse accepts a name or a number
+func enumParse(names map[int]string, kind string, text []byte, v *int) error {
+	for n, s := range names {
+		if s == string(text) {
+			*v = n
+			return nil
+		}
+	}
+	n, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("transmission: unknown %s %q", kind, text)
+	}
+	*v = n
+	return nil
+}
+
+// enumJSON writes enums as the numbers the daemon uses
+func enumJSON(v int) ([]byte, error) {
+	return []byte(strconv.Itoa(v)), nil
+}
+
+// enumUnmarshalJSON accepts the daemon's numbers as well as names
+func enumUnmarshalJSON(names map[int]string, kind string, data []byte, v *int) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return enumParse(names, kind, []byte(s), v)
+	}
+	return json.Unmarshal(data, v)
+}
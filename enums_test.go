@@ -0,0 +1,85 @@
+package transmission
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEnums(t *testing.T) {
+	Convey("Test enum names", t, func() {
+		So(StatusSeeding.String(), ShouldEqual, "seeding")
+		So(StatusCheckPending.String(), ShouldEqual, "check-wait")
+		So(Status(42).String(), ShouldEqual, "42")
+		So(ErrorTrackerWarning.String(), ShouldEqual, "tracker-warning")
+		So(PriorityLow.String(), ShouldEqual, "low")
+		So(RatioUnlimited.String(), ShouldEqual, "unlimited")
+		So(IdleSingle.String(), ShouldEqual, "single")
+		So(EncryptionPreferred.String(), ShouldEqual, "preferred")
+
+		So(len(Statuses()), ShouldEqual, len(statusNames))
+		for i, s := range Statuses() {
+			So(s, ShouldEqual, Status(i))
+		}
+	})
+
+	Convey("Test parsing enums from text", t, func() {
+		var s Status
+		So(s.UnmarshalText([]byte("downloading")), ShouldBeNil)
+		So(s, ShouldEqual, StatusDownloading)
+		So(s.UnmarshalText([]byte("6")), ShouldBeNil)
+		So(s, ShouldEqual, StatusSeeding)
+		So(s.UnmarshalText([]byte("sleeping")), ShouldNotBeNil)
+
+		var p Priority
+		So(p.UnmarshalText([]byte("high")), ShouldBeNil)
+		So(p, ShouldEqual, PriorityHigh)
+
+		var e Encryption
+		So(e.UnmarshalText([]byte("tolerated")), ShouldBeNil)
+		So(e, ShouldEqual, EncryptionTolerated)
+		So(e.UnmarshalText([]byte("never")), ShouldBeNil)
+		So(e, ShouldEqual, Encryption("never"))
+		So(e.Known(), ShouldBeFalse)
+		So(EncryptionPreferred.Known(), ShouldBeTrue)
+	})
+
+	Convey("Test enums use the daemon's JSON", t, func() {
+		var torrent Torrent
+		err := json.Unmarshal([]byte(`{"status":4,"error":2,"seedRatioMode":1,"seedIdleMode":2,"fileStats":[{"priority":-1}]}`), &torrent)
+		So(err, ShouldBeNil)
+		So(torrent.Status, ShouldEqual, StatusDownloading)
+		So(torrent.Error, ShouldEqual, ErrorTrackerError)
+		So(torrent.SeedRatioMode, ShouldEqual, RatioSingle)
+		So(torrent.SeedIdleMode, ShouldEqual, IdleUnlimited)
+		So(torrent.FileStats[0].Priority, ShouldEqual, PriorityLow)
+
+		data, err := json.Marshal(torrent)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, `"status":4`)
+		So(string(data), ShouldContainSubstring, `"priority":-1`)
+
+		var session Session
+		So(json.Unmarshal([]byte(`{"encryption":"required"}`), &session), ShouldBeNil)
+		So(session.Encryption, ShouldEqual, EncryptionRequired)
+		So(json.Unmarshal([]byte(`{"encryption":"opportunistic","version":"5.0.0"}`), &session), ShouldBeNil)
+		So(session.Encryption, ShouldEqual, Encryption("opportunistic"))
+		So(session.Version, ShouldEqual, "5.0.0")
+		session.Encryption = EncryptionRequired
+		data, _ = json.Marshal(SessionSettings{Encryption: &session.Encryption})
+		So(string(data), ShouldEqual, `{"encryption":"required"}`)
+	})
+
+	Convey("Test names are accepted in JSON too", t, func() {
+		var modes []RatioMode
+		So(json.Unmarshal([]byte(`["global", 2]`), &modes), ShouldBeNil)
+		So(modes, ShouldResemble, []RatioMode{RatioGlobal, RatioUnlimited})
+	})
+
+	Convey("Test enums as map keys", t, func() {
+		data, err := json.Marshal(map[Status]int{StatusSeeding: 3})
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"seeding":3}`)
+	})
+}
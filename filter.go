@@ -0,0 +1,194 @@
+package transmission
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Predicate reports whether a torrent should be selected
+type Predicate func(*Torrent) bool
+
+// Filter returns the torrents matching pred
+func (t Torrents) Filter(pred Predicate) Torrents {
+	filtered := make(Torrents, 0)
+	for i := range t {
+		if pred(t[i]) {
+			filtered = append(filtered, t[i])
+		}
+	}
+	return filtered
+}
+
+// All matches torrents matching every one of preds
+func All(preds ...Predicate) Predicate {
+	return func(t *Torrent) bool {
+		for _, pred := range preds {
+			if !pred(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any matches torrents matching at least one of preds
+func Any(preds ...Predicate) Predicate {
+	return func(t *Torrent) bool {
+		for _, pred := range preds {
+			if pred(t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches torrents not matching pred
+func Not(pred Predicate) Predicate {
+	return func(t *Torrent) bool {
+		return !pred(t)
+	}
+}
+
+// StatusIn matches torrents with one of the given statuses
+func StatusIn(statuses ...Status) Predicate {
+	return func(t *Torrent) bool {
+		for _, s := range statuses {
+			if t.Status == s {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RatioAbove matches torrents with an upload ratio above ratio, the
+// infinite ratio included
+func RatioAbove(ratio float64) Predicate {
+	return func(t *Torrent) bool {
+		return ratioKey(t) > ratio
+	}
+}
+
+// AddedBefore matches torrents added before when. Torrents without an
+// addedDate, e.g. because it wasn't fetched, never match.
+func AddedBefore(when time.Time) Predicate {
+	return func(t *Torrent) bool {
+		return !t.AddedDate.IsZero() && t.AddedDate.Before(when)
+	}
+}
+
+// TrackerHost matches torrents with a tracker on host or one of its subdomains
+func TrackerHost(host string) Predicate {
+	host = strings.ToLower(host)
+	return func(t *Torrent) bool {
+		for _, tr := range t.Trackers {
+			h := trackerHost(tr.Announce)
+			if h == host || strings.HasSuffix(h, "."+host) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasLabel matches torrents labeled label
+func HasLabel(label string) Predicate {
+	return func(t *Torrent) bool {
+		return t.HasLabel(label)
+	}
+}
+
+// NameMatches matches torrents whose name matches re
+func NameMatches(re *regexp.Regexp) Predicate {
+	return func(t *Torrent) bool {
+		return re.MatchString(t.Name)
+	}
+}
+
+// HasError matches torrents with a tracker or local error
+func HasError() Predicate {
+	return func(t *Torrent) bool {
+		return t.Error != ErrorNone
+	}
+}
+
+// DownloadDirIn matches torrents downloading into dir or below it
+func DownloadDirIn(dir string) Predicate {
+	dir = strings.TrimSuffix(dir, "/")
+	return func(t *Torrent) bool {
+		return t.DownloadDir == dir || strings.HasPrefix(t.DownloadDir, dir+"/")
+	}
+}
+
+// Query builds a predicate out of conditions that must all hold
+type Query struct {
+	preds []Predicate
+}
+
+// NewQuery returns a query matching every torrent
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds a condition to the query
+func (q *Query) Where(pred Predicate) *Query {
+	q.preds = append(q.preds, pred)
+	return q
+}
+
+// Status selects torrents with one of the given statuses
+func (q *Query) Status(statuses ...Status) *Query {
+	return q.Where(StatusIn(statuses...))
+}
+
+// RatioAbove selects torrents with an upload ratio above ratio
+func (q *Query) RatioAbove(ratio float64) *Query {
+	return q.Where(RatioAbove(ratio))
+}
+
+// AddedBefore selects torrents added before when
+func (q *Query) AddedBefore(when time.Time) *Query {
+	return q.Where(AddedBefore(when))
+}
+
+// Tracker selects torrents with a tracker on host or one of its subdomains
+func (q *Query) Tracker(host string) *Query {
+	return q.Where(TrackerHost(host))
+}
+
+// Label selects torrents labeled label
+func (q *Query) Label(label string) *Query {
+	return q.Where(HasLabel(label))
+}
+
+// Name selects torrents whose name matches re
+func (q *Query) Name(re *regexp.Regexp) *Query {
+	return q.Where(NameMatches(re))
+}
+
+// WithError selects torrents with an error
+func (q *Query) WithError() *Query {
+	return q.Where(HasError())
+}
+
+// DownloadDir selects torrents downloading into dir or below it
+func (q *Query) DownloadDir(dir string) *Query {
+	return q.Where(DownloadDirIn(dir))
+}
+
+// Predicate returns the query as a single predicate
+func (q *Query) Predicate() Predicate {
+	return All(q.preds...)
+}
+
+// Match reports whether t matches the query
+func (q *Query) Match(t *Torrent) bool {
+	return q.Predicate()(t)
+}
+
+// Filter returns the torrents matching the query
+func (q *Query) Filter(t Torrents) Torrents {
+	return t.Filter(q.Predicate())
+}
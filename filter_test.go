@@ -0,0 +1,71 @@
+package transmission
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testNow = time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+
+func testTorrents() Torrents {
+	return Torrents{
+		{ID: 1, Name: "Debian 12", Status: StatusSeeding, UploadRatio: 3.2,
+			AddedDate: testNow.AddDate(0, 0, -40), DownloadDir: "/data/linux",
+			Trackers: []tracker{{Announce: "http://bttracker.debian.org:6969/announce"}},
+			Labels:   []string{"linux-isos"}},
+		{ID: 2, Name: "Show S01E01", Status: StatusDownloading, UploadRatio: 0.1,
+			AddedDate: testNow.AddDate(0, 0, -2), DownloadDir: "/data/tv",
+			Trackers: []tracker{{Announce: "udp://tracker.example.org:1337/announce"}},
+			Labels:   []string{"tv"}},
+		{ID: 3, Name: "Film", Status: StatusStopped, UploadRatio: 2.5,
+			AddedDate: testNow.AddDate(0, 0, -100), DownloadDir: "/database",
+			Trackers: []tracker{{Announce: "https://example.org/announce"}},
+			Error:    2, ErrorString: "Tracker gave HTTP response code 404"},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	torrents := testTorrents()
+
+	Convey("Test single predicates", t, func() {
+		So(torrents.Filter(StatusIn(StatusSeeding, StatusStopped)).GetIDs(), ShouldResemble, []int{1, 3})
+		So(torrents.Filter(RatioAbove(2.5)).GetIDs(), ShouldResemble, []int{1})
+		So(torrents.Filter(AddedBefore(testNow.AddDate(0, 0, -30))).GetIDs(), ShouldResemble, []int{1, 3})
+		So(torrents.Filter(TrackerHost("example.org")).GetIDs(), ShouldResemble, []int{2, 3})
+		So(torrents.Filter(TrackerHost("debian.org")).GetIDs(), ShouldResemble, []int{1})
+		So(torrents.Filter(HasLabel("tv")).GetIDs(), ShouldResemble, []int{2})
+		So(torrents.Filter(NameMatches(regexp.MustCompile(`(?i)s\d+e\d+`))).GetIDs(), ShouldResemble, []int{2})
+		So(torrents.Filter(HasError()).GetIDs(), ShouldResemble, []int{3})
+		So(torrents.Filter(DownloadDirIn("/data")).GetIDs(), ShouldResemble, []int{1, 2})
+		So(torrents.Filter(DownloadDirIn("/data/")).GetIDs(), ShouldResemble, []int{1, 2})
+	})
+
+	Convey("Test infinite ratios and missing dates", t, func() {
+		torrents := Torrents{{ID: 1, UploadRatio: -2, AddedDate: testNow}, {ID: 2, UploadRatio: -1}}
+		So(torrents.Filter(RatioAbove(100)).GetIDs(), ShouldResemble, []int{1})
+		So(torrents.Filter(AddedBefore(testNow.AddDate(1, 0, 0))).GetIDs(), ShouldResemble, []int{1})
+	})
+
+	Convey("Test combining predicates", t, func() {
+		So(torrents.Filter(All(RatioAbove(2), Not(HasError()))).GetIDs(), ShouldResemble, []int{1})
+		So(torrents.Filter(Any(HasLabel("tv"), HasError())).GetIDs(), ShouldResemble, []int{2, 3})
+		So(torrents.Filter(All()).GetIDs(), ShouldResemble, []int{1, 2, 3})
+		So(torrents.Filter(Any()), ShouldBeEmpty)
+	})
+
+	Convey("Test building a query", t, func() {
+		q := NewQuery().
+			Status(StatusSeeding, StatusStopped).
+			RatioAbove(2).
+			AddedBefore(testNow.AddDate(0, 0, -30))
+		So(q.Filter(torrents).GetIDs(), ShouldResemble, []int{1, 3})
+
+		q.Tracker("debian.org")
+		So(q.Filter(torrents).GetIDs(), ShouldResemble, []int{1})
+		So(q.Match(torrents[0]), ShouldBeTrue)
+		So(q.Match(torrents[2]), ShouldBeFalse)
+	})
+}
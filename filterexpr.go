@@ -0,0 +1,314 @@
+package transmission
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FilterError describes a syntax error in a filter expression
+type FilterError struct {
+	Expr string
+	Pos  int // byte offset of the offending term in Expr
+	Msg  string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("transmission: invalid filter at column %d: %s", e.Pos+1, e.Msg)
+}
+
+// filterStatuses maps the status names accepted by ParseFilter, those of
+// Status.String plus "active", to statuses
+var filterStatuses = func() map[string][]Status {
+	m := map[string][]Status{
+		"active": {StatusChecking, StatusDownloading, StatusSeeding},
+	}
+	for _, s := range Statuses() {
+		m[s.String()] = []Status{s}
+	}
+	return m
+}()
+
+// ParseFilter compiles a filter expression into a Predicate. An expression
+// is a list of space separated terms that must all match, e.g.
+//
+//	status:seeding ratio>2 tracker:example.org age>30d
+//
+// A term is a key, an operator and a value. Values with spaces can be
+// double quoted, and a term prefixed with "-" matches the opposite. Keys:
+//
+//	status:NAME[,NAME]  stopped, check-wait, checking, download-wait,
+//	                    downloading, seed-wait, seeding or active
+//	ratio OP N          upload ratio
+//	age OP DURATION     time since added, e.g. 90m, 12h, 30d, 2w
+//	size OP SIZE        size when done, e.g. 700MB, 4.7GiB
+//	progress OP N       percent done, 0 to 100
+//	id OP N             torrent id
+//	name:TEXT           name contains TEXT, ignoring case; name:/RE/ for a regexp
+//	tracker:HOST        a tracker is on HOST or one of its subdomains
+//	label:LABEL         labeled LABEL
+//	dir:PATH            downloading into PATH or below it
+//	error:yes|no        has an error
+//
+// OP is one of : = < <= > >=, where : and = both mean equal.
+func ParseFilter(expr string) (Predicate, error) {
+	preds := make([]Predicate, 0)
+	for _, term := range splitTerms(expr) {
+		if term.err != "" {
+			return nil, &FilterError{Expr: expr, Pos: term.pos, Msg: term.err}
+		}
+		pred, err := parseTerm(term.text)
+		if err != nil {
+			return nil, &FilterError{Expr: expr, Pos: term.pos, Msg: err.Error()}
+		}
+		preds = append(preds, pred)
+	}
+	return All(preds...), nil
+}
+
+type filterTerm struct {
+	text string
+	pos  int
+	err  string
+}
+
+// splitTerms splits expr at spaces outside double quotes, removing the quotes
+func splitTerms(expr string) []filterTerm {
+	var (
+		terms   []filterTerm
+		current strings.Builder
+		start   = -1
+		quote   = -1
+	)
+	for i, r := range expr {
+		switch {
+		case r == '"':
+			if start < 0 {
+				start = i
+			}
+			if quote < 0 {
+				quote = i
+			} else {
+				quote = -1
+			}
+		case unicode.IsSpace(r) && quote < 0:
+			if start >= 0 {
+				terms = append(terms, filterTerm{text: current.String(), pos: start})
+				current.Reset()
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+			current.WriteRune(r)
+		}
+	}
+	if quote >= 0 {
+		return append(terms, filterTerm{pos: quote, err: "unterminated quote"})
+	}
+	if start >= 0 {
+		terms = append(terms, filterTerm{text: current.String(), pos: start})
+	}
+	return terms
+}
+
+var termPattern = regexp.MustCompile(`^(-?)([a-z]+)(:|=|<=|>=|<|>)(.*)$`)
+
+func parseTerm(term string) (Predicate, error) {
+	m := termPattern.FindStringSubmatch(term)
+	if m == nil {
+		return nil, fmt.Errorf("%q is not of the form key:value", term)
+	}
+	negate, key, op, value := m[1] == "-", m[2], m[3], m[4]
+	if value == "" {
+		return nil, fmt.Errorf("%s needs a value", key)
+	}
+
+	pred, err := keyPredicate(key, op, value)
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		return Not(pred), nil
+	}
+	return pred, nil
+}
+
+func keyPredicate(key, op, value string) (Predicate, error) {
+	switch key {
+	case "status":
+		if err := equalOnly(key, op); err != nil {
+			return nil, err
+		}
+		statuses := make([]Status, 0)
+		for _, name := range strings.Split(value, ",") {
+			s, ok := filterStatuses[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("unknown status %q", name)
+			}
+			statuses = append(statuses, s...)
+		}
+		return StatusIn(statuses...), nil
+
+	case "ratio":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ratio %q is not a number", value)
+		}
+		return compareFloat(op, n, ratioKey), nil
+
+	case "progress":
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("progress %q is not a number", value)
+		}
+		return compareFloat(op, n, func(t *Torrent) float64 { return t.PercentDone * 100 }), nil
+
+	case "id":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("id %q is not a number", value)
+		}
+		return compareFloat(op, float64(n), func(t *Torrent) float64 { return float64(t.ID) }), nil
+
+	case "size":
+		n, err := parseSize(value)
+		if err != nil {
+			return nil, err
+		}
+		return compareFloat(op, float64(n), func(t *Torrent) float64 { return float64(t.SizeWhenDone) }), nil
+
+	case "age":
+		d, err := parseAge(value)
+		if err != nil {
+			return nil, err
+		}
+		age := compareFloat(op, d.Seconds(), func(t *Torrent) float64 {
+			return time.Since(t.AddedDate).Seconds()
+		})
+		// a zero addedDate wasn't fetched, it isn't ancient
+		return func(t *Torrent) bool { return !t.AddedDate.IsZero() && age(t) }, nil
+
+	case "name":
+		if err := equalOnly(key, op); err != nil {
+			return nil, err
+		}
+		if len(value) > 1 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+			re, err := regexp.Compile(value[1 : len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("bad name regexp: %v", err)
+			}
+			return NameMatches(re), nil
+		}
+		return NameMatches(regexp.MustCompile("(?i)" + regexp.QuoteMeta(value))), nil
+
+	case "tracker":
+		if err := equalOnly(key, op); err != nil {
+			return nil, err
+		}
+		return TrackerHost(value), nil
+
+	case "label":
+		if err := equalOnly(key, op); err != nil {
+			return nil, err
+		}
+		return HasLabel(value), nil
+
+	case "dir":
+		if err := equalOnly(key, op); err != nil {
+			return nil, err
+		}
+		return DownloadDirIn(value), nil
+
+	case "error":
+		if err := equalOnly(key, op); err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(value) {
+		case "yes", "true":
+			return HasError(), nil
+		case "no", "false":
+			return Not(HasError()), nil
+		}
+		return nil, fmt.Errorf("error must be yes or no, not %q", value)
+	}
+	return nil, fmt.Errorf("unknown key %q", key)
+}
+
+func equalOnly(key, op string) error {
+	if op != ":" && op != "=" {
+		return fmt.Errorf("%s can't be compared with %s", key, op)
+	}
+	return nil
+}
+
+// compareFloat matches torrents for which field(t) OP n holds
+func compareFloat(op string, n float64, field func(*Torrent) float64) Predicate {
+	return func(t *Torrent) bool {
+		v := field(t)
+		switch op {
+		case "<":
+			return v < n
+		case "<=":
+			return v <= n
+		case ">":
+			return v > n
+		case ">=":
+			return v >= n
+		}
+		return v == n
+	}
+}
+
+var ageUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseAge parses durations like "30d" or "1.5h"
+func parseAge(value string) (time.Duration, error) {
+	i := strings.LastIndexFunc(value, unicode.IsDigit) + 1
+	unit, ok := ageUnits[value[i:]]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("age %q needs a unit: s, m, h, d or w", value)
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("age %q is not a number", value)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseSize parses sizes like "700MB" or "4.7GiB" into bytes
+func parseSize(value string) (uint64, error) {
+	i := strings.LastIndexFunc(value, unicode.IsDigit) + 1
+	unit, ok := sizeUnits[strings.ToLower(value[i:])]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("size %q has an unknown unit", value)
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("size %q is not a number", value)
+	}
+	return uint64(n * unit), nil
+}
@@ -0,0 +1,85 @@
+package transmission
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseFilter(t *testing.T) {
+	torrents := testTorrents()
+	now := time.Now()
+	for i, age := range []int{40, 2, 100} {
+		torrents[i].AddedDate = now.AddDate(0, 0, -age)
+	}
+	torrents[0].SizeWhenDone = 600 << 20
+	torrents[1].SizeWhenDone = 350 << 20
+	torrents[1].PercentDone = 0.42
+
+	match := func(expr string) []int {
+		pred, err := ParseFilter(expr)
+		So(err, ShouldBeNil)
+		return torrents.Filter(pred).GetIDs()
+	}
+
+	Convey("Test infinite ratios and missing dates", t, func() {
+		others := Torrents{{ID: 4, UploadRatio: -2, AddedDate: now}, {ID: 5, UploadRatio: -1}}
+		pred, err := ParseFilter("ratio>2")
+		So(err, ShouldBeNil)
+		So(others.Filter(pred).GetIDs(), ShouldResemble, []int{4})
+		pred, err = ParseFilter("age>30d")
+		So(err, ShouldBeNil)
+		So(others.Filter(pred), ShouldBeEmpty)
+	})
+
+	Convey("Test the documented example", t, func() {
+		So(match("status:seeding ratio>2 tracker:debian.org age>30d"), ShouldResemble, []int{1})
+	})
+
+	Convey("Test each key", t, func() {
+		So(match("status:seeding,stopped"), ShouldResemble, []int{1, 3})
+		So(match("status:active"), ShouldResemble, []int{1, 2})
+		So(match("ratio>=2.5"), ShouldResemble, []int{1, 3})
+		So(match("ratio<1"), ShouldResemble, []int{2})
+		So(match("age<1w"), ShouldResemble, []int{2})
+		So(match("age>=60d"), ShouldResemble, []int{3})
+		So(match("size>500MiB"), ShouldResemble, []int{1})
+		So(match("size<=400mib"), ShouldResemble, []int{2, 3})
+		So(match("progress>40%"), ShouldResemble, []int{2})
+		So(match("id=2"), ShouldResemble, []int{2})
+		So(match("name:debian"), ShouldResemble, []int{1})
+		So(match(`name:"/^S\w+ S\d+E\d+$/"`), ShouldResemble, []int{2})
+		So(match(`name:"show s01"`), ShouldResemble, []int{2})
+		So(match("tracker:example.org"), ShouldResemble, []int{2, 3})
+		So(match("label:tv"), ShouldResemble, []int{2})
+		So(match("dir:/data"), ShouldResemble, []int{1, 2})
+		So(match("error:yes"), ShouldResemble, []int{3})
+		So(match("error:no"), ShouldResemble, []int{1, 2})
+	})
+
+	Convey("Test negation and empty expressions", t, func() {
+		So(match("-label:tv -error:yes"), ShouldResemble, []int{1})
+		So(match("   "), ShouldResemble, []int{1, 2, 3})
+	})
+
+	Convey("Test syntax errors point at the bad term", t, func() {
+		for expr, pos := range map[string]int{
+			"ratio>2 colour:red":      8,
+			"status:sleeping":         0,
+			"ratio>lots":              0,
+			"age>30":                  0,
+			"size>3parsecs":           0,
+			"name:/(/":                0,
+			"label>tv":                0,
+			"status:seeding ratio":    15,
+			"error:maybe":             0,
+			`name:"unterminated`:      5,
+			"status:seeding tracker:": 15,
+		} {
+			_, err := ParseFilter(expr)
+			So(err, ShouldNotBeNil)
+			So(err.(*FilterError).Pos, ShouldEqual, pos)
+		}
+	})
+}
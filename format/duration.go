@@ -0,0 +1,104 @@
+package format
+
+import (
+	"strconv"
+	"time"
+)
+
+var durationUnits = []struct {
+	suffix string
+	d      time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// Duration formats d using its two largest units, e.g. "2d 3h", "5m 10s"
+// or "45s". Durations below a second are shown as "0s".
+func Duration(d time.Duration) string {
+	if d < 0 {
+		return "-" + Duration(-d)
+	}
+	d = d.Round(time.Second)
+
+	out := ""
+	parts := 0
+	for _, u := range durationUnits {
+		if d < u.d && parts == 0 {
+			continue
+		}
+		n := d / u.d
+		d -= n * u.d
+		if n > 0 {
+			if out != "" {
+				out += " "
+			}
+			out += strconv.FormatInt(int64(n), 10) + u.suffix
+		}
+		if parts++; parts == 2 {
+			break
+		}
+	}
+	if out == "" {
+		return "0s"
+	}
+	return out
+}
+
+// ETA formats the time left for a download like Duration, negative values
+// mean it's unknown and are shown as "∞"
+func ETA(d time.Duration) string {
+	if d < 0 {
+		return "∞"
+	}
+	return Duration(d)
+}
+
+var relativeUnits = []struct {
+	name string
+	d    time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// Relative describes t relative to now in its largest unit, e.g.
+// "3 hours ago" or "in 2 days". Anything within 10 seconds is "just now".
+func Relative(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < 10*time.Second {
+		return "just now"
+	}
+
+	for _, u := range relativeUnits {
+		if d < u.d {
+			continue
+		}
+		n := int64(d / u.d)
+		s := strconv.FormatInt(n, 10) + " " + u.name
+		if n != 1 {
+			s += "s"
+		}
+		if future {
+			return "in " + s
+		}
+		return s + " ago"
+	}
+	return "just now"
+}
+
+// Since is Relative to the current time
+func Since(t time.Time) string {
+	return Relative(t, time.Now())
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDuration(t *testing.T) {
+	Convey("Test compact durations", t, func() {
+		So(Duration(0), ShouldEqual, "0s")
+		So(Duration(400*time.Millisecond), ShouldEqual, "0s")
+		So(Duration(45*time.Second), ShouldEqual, "45s")
+		So(Duration(5*time.Minute+10*time.Second), ShouldEqual, "5m 10s")
+		So(Duration(time.Hour+2*time.Minute+3*time.Second), ShouldEqual, "1h 2m")
+		So(Duration(51*time.Hour+59*time.Minute), ShouldEqual, "2d 3h")
+		So(Duration(48*time.Hour+30*time.Second), ShouldEqual, "2d")
+		So(Duration(-90*time.Second), ShouldEqual, "-1m 30s")
+	})
+
+	Convey("Test ETAs", t, func() {
+		So(ETA(90*time.Minute), ShouldEqual, "1h 30m")
+		So(ETA(-time.Second), ShouldEqual, "∞")
+	})
+
+	Convey("Test relative times", t, func() {
+		now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		So(Relative(now.Add(-5*time.Second), now), ShouldEqual, "just now")
+		So(Relative(now.Add(-45*time.Second), now), ShouldEqual, "45 seconds ago")
+		So(Relative(now.Add(-time.Minute), now), ShouldEqual, "1 minute ago")
+		So(Relative(now.Add(-3*time.Hour-59*time.Minute), now), ShouldEqual, "3 hours ago")
+		So(Relative(now.Add(-8*24*time.Hour), now), ShouldEqual, "1 week ago")
+		So(Relative(now.Add(-400*24*time.Hour), now), ShouldEqual, "1 year ago")
+		So(Relative(now.Add(49*time.Hour), now), ShouldEqual, "in 2 days")
+		So(Since(time.Now()), ShouldEqual, "just now")
+	})
+}
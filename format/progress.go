@@ -0,0 +1,49 @@
+package format
+
+import "strings"
+
+// eighths are the partial blocks filling a cell from the left
+var eighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// ProgressBar draws fraction, between 0 and 1, as a bar of width cells
+// using block characters with eighth-cell precision
+func ProgressBar(fraction float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	filled := int(fraction*float64(width*8) + 0.5)
+	full, part := filled/8, filled%8
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat("█", full))
+	cells := full
+	if part > 0 {
+		b.WriteString(eighths[part])
+		cells++
+	}
+	b.WriteString(strings.Repeat(" ", width-cells))
+	return b.String()
+}
+
+// ProgressBarASCII draws fraction as a bar of width cells of '#' and '.',
+// for terminals without Unicode
+func ProgressBarASCII(fraction float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	filled := int(fraction*float64(width) + 0.5)
+	return strings.Repeat("#", filled) + strings.Repeat(".", width-filled)
+}
@@ -0,0 +1,27 @@
+package format
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProgressBar(t *testing.T) {
+	Convey("Test block progress bars", t, func() {
+		So(ProgressBar(0, 4), ShouldEqual, "    ")
+		So(ProgressBar(1, 4), ShouldEqual, "████")
+		So(ProgressBar(0.5, 4), ShouldEqual, "██  ")
+		So(ProgressBar(0.3, 4), ShouldEqual, "█▎  ")
+		So(ProgressBar(2, 3), ShouldEqual, "███")
+		So(ProgressBar(0.5, 0), ShouldEqual, "")
+		for _, f := range []float64{0, 0.01, 0.33, 0.99, 1} {
+			So(utf8.RuneCountInString(ProgressBar(f, 10)), ShouldEqual, 10)
+		}
+	})
+
+	Convey("Test ASCII progress bars", t, func() {
+		So(ProgressBarASCII(0.5, 10), ShouldEqual, "#####.....")
+		So(ProgressBarASCII(-1, 3), ShouldEqual, "...")
+	})
+}
@@ -0,0 +1,70 @@
+// Package format renders sizes, speeds, durations and progress for
+// people. The output doesn't depend on the locale.
+package format
+
+import "strconv"
+
+var (
+	iecUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siUnits  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// Size formats n bytes with IEC units, e.g. "1.5 MiB"
+func Size(n uint64) string {
+	return scale(n, 1024, iecUnits)
+}
+
+// SizeSI formats n bytes with SI units, e.g. "1.6 MB"
+func SizeSI(n uint64) string {
+	return scale(n, 1000, siUnits)
+}
+
+// Speed formats a rate in bytes per second with IEC units, e.g. "1.2 MiB/s"
+func Speed(n uint64) string {
+	return Size(n) + "/s"
+}
+
+// SpeedSI formats a rate in bytes per second with SI units, e.g. "1.2 MB/s"
+func SpeedSI(n uint64) string {
+	return SizeSI(n) + "/s"
+}
+
+// scale shows one decimal below 100 units and none above, e.g. "12.3 MiB"
+// and "123 MiB"
+func scale(n, base uint64, units []string) string {
+	if n < base {
+		return strconv.FormatUint(n, 10) + " " + units[0]
+	}
+	v := float64(n)
+	i := 0
+	for v >= float64(base) && i < len(units)-1 {
+		v /= float64(base)
+		i++
+	}
+	// rounding may carry into the next unit, e.g. 1023.96 KiB
+	if v >= float64(base)-0.05 && i < len(units)-1 {
+		v /= float64(base)
+		i++
+	}
+	if v >= 99.95 {
+		return strconv.FormatFloat(v, 'f', 0, 64) + " " + units[i]
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + " " + units[i]
+}
+
+// Ratio formats an upload ratio with two decimals. The daemon's -1, not
+// available, is shown as "-" and -2, infinite, as "∞".
+func Ratio(r float64) string {
+	if r == -2 {
+		return "∞"
+	}
+	if r < 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(r, 'f', 2, 64)
+}
+
+// Percent formats a fraction between 0 and 1 as a percentage, e.g. "42.5%"
+func Percent(f float64) string {
+	return strconv.FormatFloat(f*100, 'f', 1, 64) + "%"
+}
@@ -0,0 +1,41 @@
+package format
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSize(t *testing.T) {
+	Convey("Test IEC sizes", t, func() {
+		So(Size(0), ShouldEqual, "0 B")
+		So(Size(1023), ShouldEqual, "1023 B")
+		So(Size(1024), ShouldEqual, "1.0 KiB")
+		So(Size(1536), ShouldEqual, "1.5 KiB")
+		So(Size(123*1024*1024), ShouldEqual, "123 MiB")
+		So(Size(1024*1024-1), ShouldEqual, "1.0 MiB")
+		So(Size(5<<40), ShouldEqual, "5.0 TiB")
+		So(Size(1<<63), ShouldEqual, "8.0 EiB")
+	})
+
+	Convey("Test SI sizes", t, func() {
+		So(SizeSI(999), ShouldEqual, "999 B")
+		So(SizeSI(1000), ShouldEqual, "1.0 kB")
+		So(SizeSI(1572864), ShouldEqual, "1.6 MB")
+		So(SizeSI(250e9), ShouldEqual, "250 GB")
+	})
+
+	Convey("Test speeds", t, func() {
+		So(Speed(1258291), ShouldEqual, "1.2 MiB/s")
+		So(SpeedSI(1200000), ShouldEqual, "1.2 MB/s")
+		So(Speed(0), ShouldEqual, "0 B/s")
+	})
+
+	Convey("Test ratios and percentages", t, func() {
+		So(Ratio(1.234), ShouldEqual, "1.23")
+		So(Ratio(-1), ShouldEqual, "-")
+		So(Ratio(-2), ShouldEqual, "∞")
+		So(Percent(0.425), ShouldEqual, "42.5%")
+		So(Percent(1), ShouldEqual, "100.0%")
+	})
+}
@@ -0,0 +1,44 @@
+package transmission
+
+// BandwidthGroup is a named set of speed limits shared by all its torrents.
+// Speed limits are in KB/s.
+type BandwidthGroup struct {
+	Name                  string `json:"name"`
+	HonorsSessionLimits   bool   `json:"honorsSessionLimits"`
+	SpeedLimitDown        int    `json:"speed-limit-down"`
+	SpeedLimitDownEnabled bool   `json:"speed-limit-down-enabled"`
+	SpeedLimitUp          int    `json:"speed-limit-up"`
+	SpeedLimitUpEnabled   bool   `json:"speed-limit-up-enabled"`
+}
+
+// GetGroups returns the bandwidth groups with the given names, or all of
+// them when no names are given
+func (ac *TransmissionClient) GetGroups(names ...string) ([]BandwidthGroup, error) {
+	if err := ac.require(FeatureBandwidthGroups); err != nil {
+		return nil, err
+	}
+	out, err := Call[GroupGetRequest, GroupGetResponse](ac, "group-get", GroupGetRequest{Group: names})
+	if err != nil {
+		return nil, err
+	}
+	return out.Group, nil
+}
+
+// SetGroup creates or updates the bandwidth group named group.Name
+func (ac *TransmissionClient) SetGroup(group BandwidthGroup) error {
+	if err := ac.require(FeatureBandwidthGroups); err != nil {
+		return err
+	}
+	_, err := Call[BandwidthGroup, struct{}](ac, "group-set", group)
+	return err
+}
+
+// SetTorrentGroup assigns torrents to the bandwidth group named group;
+// an empty name takes them out of their group
+func (ac *TransmissionClient) SetTorrentGroup(ids []int, group string) error {
+	if err := ac.require(FeatureBandwidthGroups); err != nil {
+		return err
+	}
+	_, err := Call[TorrentSetRequest, struct{}](ac, "torrent-set", TorrentSetRequest{IDs: ids, Group: &group})
+	return err
+}
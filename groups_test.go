@@ -0,0 +1,68 @@
+package transmission
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBandwidthGroups(t *testing.T) {
+	server, requests := rpcServer(17, map[string]string{
+		"group-get": `{"group":[{"name":"tracker-a","honorsSessionLimits":true,
+  "speed-limit-down":500,"speed-limit-down-enabled":true,
+  "speed-limit-up":100,"speed-limit-up-enabled":false}]}`,
+		"torrent-get": `{"torrents":[{"id":1,"group":"tracker-a"}]}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	Convey("Test getting groups", t, func() {
+		groups, err := client.GetGroups("tracker-a")
+		So(err, ShouldBeNil)
+		So(groups, ShouldResemble, []BandwidthGroup{{
+			Name:                  "tracker-a",
+			HonorsSessionLimits:   true,
+			SpeedLimitDown:        500,
+			SpeedLimitDownEnabled: true,
+			SpeedLimitUp:          100,
+		}})
+
+		reqs := requests()
+		So(reqs[len(reqs)-1].Arguments["group"], ShouldResemble, []interface{}{"tracker-a"})
+	})
+
+	Convey("Test setting a group", t, func() {
+		err := client.SetGroup(BandwidthGroup{Name: "tracker-b", SpeedLimitUp: 50, SpeedLimitUpEnabled: true})
+		So(err, ShouldBeNil)
+
+		reqs := requests()
+		last := reqs[len(reqs)-1]
+		So(last.Method, ShouldEqual, "group-set")
+		So(last.Arguments["name"], ShouldEqual, "tracker-b")
+		So(last.Arguments["speed-limit-up"], ShouldEqual, float64(50))
+	})
+
+	Convey("Test assigning torrents to a group", t, func() {
+		err := client.SetTorrentGroup([]int{1, 2}, "tracker-a")
+		So(err, ShouldBeNil)
+
+		reqs := requests()
+		last := reqs[len(reqs)-1]
+		So(last.Method, ShouldEqual, "torrent-set")
+		So(last.Arguments["group"], ShouldEqual, "tracker-a")
+
+		torrents, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(torrents[0].Group, ShouldEqual, "tracker-a")
+	})
+
+	Convey("Test groups need Transmission 4", t, func() {
+		old, _ := rpcServer(16, nil)
+		defer old.Close()
+		oldClient, _ := NewClient(WithURL(old.URL))
+
+		_, err := oldClient.GetGroups()
+		So(errors.Is(err, ErrUnsupported), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,47 @@
+package transmission
+
+// SetLabels sets the labels of the torrent added by cmd, adding fails with
+// ErrUnsupported on daemons older than rpc-version 17
+func (cmd *TorrentAddRequest) SetLabels(labels ...string) {
+	cmd.Labels = labels
+}
+
+// SetTorrentLabels replaces the labels of the given torrents
+func (ac *TransmissionClient) SetTorrentLabels(ids []int, labels []string) error {
+	if err := ac.require(FeatureLabels); err != nil {
+		return err
+	}
+	if labels == nil {
+		// an empty list clears the labels, a missing one is ignored
+		labels = []string{}
+	}
+	_, err := Call[TorrentSetRequest, struct{}](ac, "torrent-set", TorrentSetRequest{IDs: ids, Labels: &labels})
+	return err
+}
+
+// HasLabel reports whether the torrent is labeled label
+func (t *Torrent) HasLabel(label string) bool {
+	for _, l := range t.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// WithLabel returns the torrents labeled label
+func (t Torrents) WithLabel(label string) Torrents {
+	return t.Filter(HasLabel(label))
+}
+
+// ByLabel groups the torrents by label. A torrent with several labels is
+// in several groups, one without labels is in none.
+func (t Torrents) ByLabel() map[string]Torrents {
+	groups := make(map[string]Torrents)
+	for i := range t {
+		for _, l := range t[i].Labels {
+			groups[l] = append(groups[l], t[i])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,54 @@
+package transmission
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLabels(t *testing.T) {
+	server, requests := rpcServer(17, map[string]string{
+		"torrent-get": `{"torrents":[
+  {"id":1,"name":"Show S01E01","labels":["tv"]},
+  {"id":2,"name":"Film","labels":["movies","hd"]},
+  {"id":3,"name":"debian.iso","labels":[]},
+  {"id":4,"name":"Show S01E02","labels":["tv","hd"]}]}`,
+		"torrent-add": `{"torrent-added":{"id":5,"name":"ubuntu.iso"}}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	Convey("Test torrents are grouped and filtered by label", t, func() {
+		torrents, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+
+		byLabel := torrents.ByLabel()
+		So(len(byLabel), ShouldEqual, 3)
+		So(byLabel["tv"].GetIDs(), ShouldResemble, []int{1, 4})
+		So(byLabel["hd"].GetIDs(), ShouldResemble, []int{2, 4})
+		So(byLabel["movies"].GetIDs(), ShouldResemble, []int{2})
+
+		So(torrents.WithLabel("tv").GetIDs(), ShouldResemble, []int{1, 4})
+		So(torrents.WithLabel("linux-isos"), ShouldBeEmpty)
+	})
+
+	Convey("Test adding a torrent with labels", t, func() {
+		cmd := NewAddCmdByURL("http://example.org/ubuntu.torrent")
+		cmd.SetLabels("linux-isos")
+		_, err := client.ExecuteAddCommand(cmd)
+		So(err, ShouldBeNil)
+
+		reqs := requests()
+		So(reqs[len(reqs)-1].Arguments["labels"], ShouldResemble, []interface{}{"linux-isos"})
+	})
+
+	Convey("Test setting and clearing labels", t, func() {
+		So(client.SetTorrentLabels([]int{3}, []string{"linux-isos"}), ShouldBeNil)
+		reqs := requests()
+		So(reqs[len(reqs)-1].Arguments["labels"], ShouldResemble, []interface{}{"linux-isos"})
+
+		So(client.SetTorrentLabels([]int{3}, nil), ShouldBeNil)
+		reqs = requests()
+		So(reqs[len(reqs)-1].Arguments["labels"], ShouldResemble, []interface{}{})
+	})
+}
@@ -0,0 +1,164 @@
+package transmission
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+// MetaInfo is the part of a .torrent file needed to plan an add
+type MetaInfo struct {
+	Name      string
+	TotalSize uint64
+	Files     []MetaInfoFile
+}
+
+// MetaInfoFile is a file listed in a .torrent, Path is relative to the torrent's directory
+type MetaInfoFile struct {
+	Path   []string
+	Length uint64
+}
+
+// ParseTorrentFile reads and parses a .torrent file
+func ParseTorrentFile(file string) (*MetaInfo, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMetaInfo(data)
+}
+
+// ParseMetaInfo parses the bencoded contents of a .torrent file
+func ParseMetaInfo(data []byte) (*MetaInfo, error) {
+	v, rest, err := bdecode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("transmission: trailing data after metainfo")
+	}
+	root, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("transmission: metainfo is not a dictionary")
+	}
+	info, ok := root["info"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("transmission: metainfo has no info dictionary")
+	}
+
+	mi := &MetaInfo{}
+	if name, ok := info["name"].(string); ok {
+		mi.Name = name
+	}
+
+	if length, ok := info["length"].(int64); ok {
+		// single file torrent
+		if length < 0 {
+			return nil, errors.New("transmission: negative length in metainfo")
+		}
+		mi.Files = []MetaInfoFile{{Path: []string{mi.Name}, Length: uint64(length)}}
+		mi.TotalSize = uint64(length)
+		return mi, nil
+	}
+
+	files, ok := info["files"].([]interface{})
+	if !ok {
+		return nil, errors.New("transmission: metainfo has neither length nor files")
+	}
+	for _, f := range files {
+		file, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("transmission: malformed file entry in metainfo")
+		}
+		length, _ := file["length"].(int64)
+		if length < 0 {
+			return nil, errors.New("transmission: negative length in metainfo")
+		}
+		var path []string
+		parts, _ := file["path"].([]interface{})
+		for _, p := range parts {
+			if s, ok := p.(string); ok {
+				path = append(path, s)
+			}
+		}
+		mi.Files = append(mi.Files, MetaInfoFile{Path: path, Length: uint64(length)})
+		mi.TotalSize += uint64(length)
+	}
+	return mi, nil
+}
+
+// bdecode decodes one bencoded value from the start of data, returning it
+// and whatever follows it. Strings become string, integers int64, lists
+// []interface{} and dictionaries map[string]interface{}.
+func bdecode(data []byte) (interface{}, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.New("transmission: unexpected end of metainfo")
+	}
+
+	switch c := data[0]; {
+	case c == 'i':
+		end := bytes.IndexByte(data, 'e')
+		if end < 0 {
+			return nil, nil, errors.New("transmission: unterminated integer in metainfo")
+		}
+		n, err := strconv.ParseInt(string(data[1:end]), 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transmission: bad integer in metainfo: %v", err)
+		}
+		return n, data[end+1:], nil
+
+	case c == 'l':
+		list := make([]interface{}, 0)
+		data = data[1:]
+		for len(data) > 0 && data[0] != 'e' {
+			v, rest, err := bdecode(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			list = append(list, v)
+			data = rest
+		}
+		if len(data) == 0 {
+			return nil, nil, errors.New("transmission: unterminated list in metainfo")
+		}
+		return list, data[1:], nil
+
+	case c == 'd':
+		dict := make(map[string]interface{})
+		data = data[1:]
+		for len(data) > 0 && data[0] != 'e' {
+			k, rest, err := bdecode(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, nil, errors.New("transmission: dictionary key in metainfo is not a string")
+			}
+			v, rest, err := bdecode(rest)
+			if err != nil {
+				return nil, nil, err
+			}
+			dict[key] = v
+			data = rest
+		}
+		if len(data) == 0 {
+			return nil, nil, errors.New("transmission: unterminated dictionary in metainfo")
+		}
+		return dict, data[1:], nil
+
+	case c >= '0' && c <= '9':
+		colon := bytes.IndexByte(data, ':')
+		if colon < 0 {
+			return nil, nil, errors.New("transmission: bad string length in metainfo")
+		}
+		n, err := strconv.Atoi(string(data[:colon]))
+		if err != nil || n < 0 || n > len(data)-colon-1 {
+			return nil, nil, errors.New("transmission: bad string length in metainfo")
+		}
+		return string(data[colon+1 : colon+1+n]), data[colon+1+n:], nil
+	}
+	return nil, nil, fmt.Errorf("transmission: unexpected %q in metainfo", data[0])
+}
@@ -0,0 +1,176 @@
+// Package metrics exposes transmission session and torrent statistics in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tubbebubbe/transmission"
+)
+
+// DefaultNamespace prefixes every metric name unless changed
+const DefaultNamespace = "transmission"
+
+// Source is what the Exporter scrapes, usually a *transmission.TransmissionClient
+type Source interface {
+	GetStats() (*transmission.Stats, error)
+	GetTorrents() (transmission.Torrents, error)
+}
+
+// Exporter scrapes a Source and writes what it finds as metrics. Set the
+// exported fields before the first scrape.
+type Exporter struct {
+	// Namespace prefixes metric names, DefaultNamespace if empty
+	Namespace string
+
+	// PerTorrent enables the per-torrent metrics
+	PerTorrent bool
+	// MaxTorrents limits per-torrent metrics to the busiest torrents,
+	// 0 means no limit
+	MaxTorrents int
+	// TorrentFilter limits per-torrent metrics to matching torrents
+	TorrentFilter transmission.Predicate
+	// TorrentNames adds a name label to per-torrent metrics
+	TorrentNames bool
+
+	source Source
+
+	mu           sync.Mutex // serializes scrapes
+	scrapeErrors int
+}
+
+// NewExporter returns an Exporter scraping src
+func NewExporter(src Source) *Exporter {
+	return &Exporter{source: src}
+}
+
+// ServeHTTP scrapes the source and replies with the metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := e.Write(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// Write scrapes the source and writes the metrics to w. A failing scrape
+// is reported through the up and scrape error metrics, only errors writing
+// to w are returned.
+func (e *Exporter) Write(w io.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := time.Now()
+	mw := NewWriter(w)
+
+	up := 1.0
+	stats, err := e.source.GetStats()
+	if err == nil {
+		e.writeStats(mw, stats)
+		var torrents transmission.Torrents
+		torrents, err = e.source.GetTorrents()
+		if err == nil {
+			e.writeTorrents(mw, torrents)
+		}
+	}
+	if err != nil {
+		up = 0
+		e.scrapeErrors++
+	}
+
+	mw.Metric(e.name("up"), Gauge, "Whether the last scrape of transmission succeeded.", up)
+	mw.Metric(e.name("scrape_errors_total"), Counter, "Number of failed scrapes.", float64(e.scrapeErrors))
+	mw.Metric(e.name("scrape_duration_seconds"), Gauge, "How long the last scrape took.", time.Since(start).Seconds())
+	return mw.Flush()
+}
+
+func (e *Exporter) name(s string) string {
+	if e.Namespace == "" {
+		return DefaultNamespace + "_" + s
+	}
+	return e.Namespace + "_" + s
+}
+
+func (e *Exporter) writeStats(mw *Writer, stats *transmission.Stats) {
+	mw.Metric(e.name("session_download_speed_bytes"), Gauge, "Current download speed in bytes per second.", float64(stats.DownloadSpeed))
+	mw.Metric(e.name("session_upload_speed_bytes"), Gauge, "Current upload speed in bytes per second.", float64(stats.UploadSpeed))
+	mw.Metric(e.name("session_active_torrents"), Gauge, "Number of active torrents.", float64(stats.ActiveTorrentCount))
+	mw.Metric(e.name("session_paused_torrents"), Gauge, "Number of paused torrents.", float64(stats.PausedTorrentCount))
+	mw.Metric(e.name("session_downloaded_bytes_total"), Counter, "Bytes downloaded over all sessions.", float64(stats.CumulativeStats.DownloadedBytes))
+	mw.Metric(e.name("session_uploaded_bytes_total"), Counter, "Bytes uploaded over all sessions.", float64(stats.CumulativeStats.UploadedBytes))
+	mw.Metric(e.name("session_files_added_total"), Counter, "Files added over all sessions.", float64(stats.CumulativeStats.FilesAdded))
+	mw.Metric(e.name("session_active_seconds_total"), Counter, "Seconds active over all sessions.", stats.CumulativeStats.SecondsActive.Seconds())
+	mw.Metric(e.name("session_count_total"), Counter, "Number of times the daemon was started.", float64(stats.CumulativeStats.SessionCount))
+}
+
+func (e *Exporter) writeTorrents(mw *Writer, torrents transmission.Torrents) {
+	totals := torrents.Aggregate()
+	name := e.name("torrents")
+	mw.Header(name, Gauge, "Number of torrents by status.")
+	for _, status := range transmission.Statuses() {
+		mw.Sample(name, Labels{"status": status.String()}, float64(totals.ByStatus[status]))
+	}
+	mw.Metric(e.name("torrents_size_bytes"), Gauge, "Total size of the wanted data of all torrents.", float64(totals.Size))
+	mw.Metric(e.name("torrents_have_bytes"), Gauge, "Data of all torrents downloaded so far.", float64(totals.Have))
+	mw.Metric(e.name("torrents_ratio"), Gauge, "Upload ratio over all torrents, -1 if nothing was downloaded.", totals.Ratio)
+
+	if !e.PerTorrent {
+		return
+	}
+	torrents = e.selectTorrents(torrents)
+	e.writePerTorrent(mw, torrents, "torrent_ratio", "Upload ratio of the torrent.", func(t *transmission.Torrent) float64 {
+		return t.UploadRatio
+	})
+	e.writePerTorrent(mw, torrents, "torrent_progress_ratio", "Fraction of wanted data downloaded.", func(t *transmission.Torrent) float64 {
+		return t.PercentDone
+	})
+	e.writePerTorrent(mw, torrents, "torrent_download_speed_bytes", "Download speed of the torrent in bytes per second.", func(t *transmission.Torrent) float64 {
+		return float64(t.RateDownload)
+	})
+	e.writePerTorrent(mw, torrents, "torrent_upload_speed_bytes", "Upload speed of the torrent in bytes per second.", func(t *transmission.Torrent) float64 {
+		return float64(t.RateUpload)
+	})
+}
+
+// selectTorrents applies TorrentFilter and MaxTorrents, keeping the
+// torrents with the highest combined rate and ordering them by id
+func (e *Exporter) selectTorrents(torrents transmission.Torrents) transmission.Torrents {
+	if e.TorrentFilter != nil {
+		torrents = torrents.Filter(e.TorrentFilter)
+	} else {
+		torrents = append(transmission.Torrents(nil), torrents...)
+	}
+	if e.MaxTorrents > 0 && len(torrents) > e.MaxTorrents {
+		sort.SliceStable(torrents, func(i, j int) bool {
+			return torrents[i].RateDownload+torrents[i].RateUpload > torrents[j].RateDownload+torrents[j].RateUpload
+		})
+		torrents = torrents[:e.MaxTorrents]
+	}
+	sort.SliceStable(torrents, func(i, j int) bool {
+		return torrents[i].ID < torrents[j].ID
+	})
+	return torrents
+}
+
+func (e *Exporter) writePerTorrent(mw *Writer, torrents transmission.Torrents, name, help string, value func(*transmission.Torrent) float64) {
+	if len(torrents) == 0 {
+		return
+	}
+	name = e.name(name)
+	mw.Header(name, Gauge, help)
+	for _, t := range torrents {
+		labels := Labels{"id": strconv.Itoa(t.ID)}
+		if e.TorrentNames {
+			labels["name"] = t.Name
+		}
+		mw.Sample(name, labels, value(t))
+	}
+}
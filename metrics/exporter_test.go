@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/tubbebubbe/transmission"
+)
+
+type fakeSource struct {
+	stats    *transmission.Stats
+	torrents transmission.Torrents
+	err      error
+}
+
+func (s *fakeSource) GetStats() (*transmission.Stats, error) {
+	return s.stats, s.err
+}
+
+func (s *fakeSource) GetTorrents() (transmission.Torrents, error) {
+	return s.torrents, s.err
+}
+
+func newFakeSource() *fakeSource {
+	stats := &transmission.Stats{
+		ActiveTorrentCount: 2,
+		DownloadSpeed:      1500,
+		UploadSpeed:        250,
+		TorrentCount:       3,
+	}
+	stats.CumulativeStats.DownloadedBytes = 1 << 40
+	stats.CumulativeStats.UploadedBytes = 12345
+	return &fakeSource{
+		stats: stats,
+		torrents: transmission.Torrents{
+			{ID: 1, Name: `Big "Buck" Bunny`, Status: transmission.StatusSeeding, UploadRatio: 2.5, PercentDone: 1, RateUpload: 100},
+			{ID: 2, Name: "Sintel", Status: transmission.StatusDownloading, PercentDone: 0.25, RateDownload: 1500},
+			{ID: 3, Name: "Tears of Steel", Status: transmission.StatusStopped, PercentDone: 0.5},
+		},
+	}
+}
+
+func scrape(e *Exporter) string {
+	var buf bytes.Buffer
+	So(e.Write(&buf), ShouldBeNil)
+	return buf.String()
+}
+
+func TestExporter(t *testing.T) {
+	Convey("Test session metrics and counts by status", t, func() {
+		out := scrape(NewExporter(newFakeSource()))
+		So(out, ShouldContainSubstring, "# TYPE transmission_session_download_speed_bytes gauge\ntransmission_session_download_speed_bytes 1500\n")
+		So(out, ShouldContainSubstring, "transmission_session_downloaded_bytes_total 1.099511627776e+12\n")
+		So(out, ShouldContainSubstring, "# TYPE transmission_session_uploaded_bytes_total counter\n")
+		So(out, ShouldContainSubstring, `transmission_torrents{status="seeding"} 1`)
+		So(out, ShouldContainSubstring, `transmission_torrents{status="check-wait"} 0`)
+		So(out, ShouldContainSubstring, "transmission_torrents_size_bytes 0\n")
+		So(out, ShouldContainSubstring, "transmission_torrents_ratio -1\n")
+		So(out, ShouldContainSubstring, "transmission_up 1\n")
+		So(out, ShouldContainSubstring, "transmission_scrape_errors_total 0\n")
+		So(out, ShouldContainSubstring, "transmission_scrape_duration_seconds ")
+		So(out, ShouldNotContainSubstring, "transmission_torrent_ratio")
+	})
+
+	Convey("Test per-torrent metrics", t, func() {
+		e := NewExporter(newFakeSource())
+		e.Namespace = "bt"
+		e.PerTorrent = true
+		e.TorrentNames = true
+		out := scrape(e)
+		So(out, ShouldContainSubstring, `bt_torrent_ratio{id="1",name="Big \"Buck\" Bunny"} 2.5`)
+		So(out, ShouldContainSubstring, `bt_torrent_progress_ratio{id="2",name="Sintel"} 0.25`)
+		So(out, ShouldContainSubstring, `bt_torrent_download_speed_bytes{id="2",name="Sintel"} 1500`)
+	})
+
+	Convey("Test cardinality controls", t, func() {
+		e := NewExporter(newFakeSource())
+		e.PerTorrent = true
+		e.MaxTorrents = 2
+		out := scrape(e)
+		So(out, ShouldContainSubstring, `transmission_torrent_ratio{id="1"} 2.5`)
+		So(out, ShouldContainSubstring, `transmission_torrent_ratio{id="2"} 0`)
+		So(out, ShouldNotContainSubstring, `{id="3"}`)
+
+		e.MaxTorrents = 0
+		e.TorrentFilter = transmission.StatusIn(transmission.StatusStopped)
+		out = scrape(e)
+		So(out, ShouldContainSubstring, `transmission_torrent_progress_ratio{id="3"} 0.5`)
+		So(out, ShouldNotContainSubstring, `{id="1"}`)
+	})
+
+	Convey("Test failing scrapes are counted", t, func() {
+		src := newFakeSource()
+		src.err = errors.New("connection refused")
+		e := NewExporter(src)
+		scrape(e)
+		out := scrape(e)
+		So(out, ShouldContainSubstring, "transmission_up 0\n")
+		So(out, ShouldContainSubstring, "transmission_scrape_errors_total 2\n")
+		So(out, ShouldNotContainSubstring, "transmission_session_")
+	})
+
+	Convey("Test serving over HTTP", t, func() {
+		rec := httptest.NewRecorder()
+		NewExporter(newFakeSource()).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		So(rec.Code, ShouldEqual, 200)
+		So(rec.Header().Get("Content-Type"), ShouldStartWith, "text/plain; version=0.0.4")
+		So(strings.HasSuffix(rec.Body.String(), "\n"), ShouldBeTrue)
+	})
+}
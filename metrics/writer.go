@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metric types of the text exposition format
+const (
+	Counter = "counter"
+	Gauge   = "gauge"
+)
+
+// Labels attached to a sample
+type Labels map[string]string
+
+// Writer writes metrics in the Prometheus text exposition format
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter returns a Writer writing to w. Call Flush when done.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Header writes the HELP and TYPE lines of a metric family
+func (w *Writer) Header(name, typ, help string) {
+	w.write("# HELP " + name + " " + escapeHelp(help) + "\n")
+	w.write("# TYPE " + name + " " + typ + "\n")
+}
+
+// Sample writes one sample of a metric family
+func (w *Writer) Sample(name string, labels Labels, value float64) {
+	w.write(name + formatLabels(labels) + " " + formatValue(value) + "\n")
+}
+
+// Metric writes a metric family with a single sample
+func (w *Writer) Metric(name, typ, help string, value float64) {
+	w.Header(name, typ, help)
+	w.Sample(name, nil, value)
+}
+
+// Flush writes any buffered data and returns the first error seen
+func (w *Writer) Flush() error {
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	return w.err
+}
+
+func (w *Writer) write(s string) {
+	if w.err == nil {
+		_, w.err = w.w.WriteString(s)
+	}
+}
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + `="` + escapeLabel(labels[k]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,162 @@
+package transmission
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultURL is where NewClient looks for the daemon unless WithURL is given
+const DefaultURL = "http://localhost:9091/transmission/rpc"
+
+// Logger is satisfied by *log.Logger
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, v ...interface{}) {}
+
+// RetryPolicy controls how failed requests are retried. Requests are
+// retried on transport errors and 5xx responses, waiting Backoff before
+// the first retry and doubling it each time up to MaxBackoff.
+// The zero value sends every request once.
+//
+// A request the daemon got before the connection failed is sent again too,
+// so methods that aren't idempotent, like torrent-add and torrent-remove,
+// may run twice.
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type clientOptions struct {
+	url          string
+	credentials  CredentialProvider
+	httpClient   *http.Client
+	logger       Logger
+	retry        RetryPolicy
+	fields       []string
+	protocol     Protocol
+	connectCheck bool
+}
+
+// Option configures a client created by NewClient
+type Option func(*clientOptions)
+
+// WithURL sets the RPC endpoint, e.g. "http://127.0.0.1:9091/transmission/rpc"
+func WithURL(url string) Option {
+	return func(o *clientOptions) {
+		o.url = url
+	}
+}
+
+// WithAuth authenticates with a fixed username and password
+func WithAuth(username, password string) Option {
+	return WithCredentials(StaticCredentials(username, password))
+}
+
+// WithCredentials authenticates with credentials from provider
+func WithCredentials(provider CredentialProvider) Option {
+	return func(o *clientOptions) {
+		o.credentials = provider
+	}
+}
+
+// WithHTTPClient sends requests with client instead of a default http.Client
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithLogger logs retries and session id changes to logger
+func WithLogger(logger Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithRetry retries failed requests according to policy
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retry = policy
+	}
+}
+
+// WithDefaultFields sets the torrent fields requested by GetTorrents and
+// GetTorrent. "id" is always requested.
+func WithDefaultFields(fields ...string) Option {
+	return func(o *clientOptions) {
+		o.fields = fields
+	}
+}
+
+// WithProtocol forces the dialect used to talk to the daemon instead of
+// detecting it from the daemon's rpc-version
+func WithProtocol(p Protocol) Option {
+	return func(o *clientOptions) {
+		o.protocol = p
+	}
+}
+
+// WithConnectCheck makes NewClient talk to the daemon before returning
+// when eager is true. By default the first request is the first contact.
+func WithConnectCheck(eager bool) Option {
+	return func(o *clientOptions) {
+		o.connectCheck = eager
+	}
+}
+
+// NewClient creates a client configured by opts. Unless WithConnectCheck(true)
+// is given it doesn't contact the daemon, so it can be called while the
+// daemon is down.
+func NewClient(opts ...Option) (*TransmissionClient, error) {
+	o := clientOptions{
+		url:         DefaultURL,
+		credentials: StaticCredentials("", ""),
+		httpClient:  &http.Client{},
+		logger:      nopLogger{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if _, err := url.Parse(o.url); err != nil {
+		return nil, err
+	}
+
+	apiclient := NewApiClient(o.url, "", "")
+	apiclient.credentials = o.credentials
+	apiclient.client = o.httpClient
+	apiclient.logger = o.logger
+	apiclient.retry = o.retry
+
+	client := &TransmissionClient{
+		apiclient: apiclient,
+		protoMode: o.protocol,
+	}
+	client.proto = newProtocol(o.protocol)
+	if len(o.fields) > 0 {
+		client.fields = withID(o.fields)
+	}
+
+	if o.connectCheck {
+		if err := client.Ping(); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// withID returns fields with "id" added if it's missing
+func withID(fields []string) []string {
+	for _, f := range fields {
+		if f == "id" {
+			return fields
+		}
+	}
+	return append([]string{"id"}, fields...)
+}
@@ -0,0 +1,114 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewClient(t *testing.T) {
+	Convey("Test a lazy client can be created while the daemon is down", t, func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		client, err := NewClient(WithURL(server.URL))
+		So(err, ShouldBeNil)
+		So(client, ShouldNotBeNil)
+		So(client.Ping(), ShouldNotBeNil)
+	})
+
+	Convey("Test an eager client fails while the daemon is down", t, func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		client, err := NewClient(WithURL(server.URL), WithConnectCheck(true))
+		So(err, ShouldNotBeNil)
+		So(client, ShouldBeNil)
+	})
+
+	Convey("Test retrying server errors", t, func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				http.Error(res, "busy", http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(res, `{"arguments":{},"result":"success"}`)
+		}))
+		defer server.Close()
+
+		client, err := NewClient(
+			WithURL(server.URL),
+			WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}),
+			WithConnectCheck(true),
+		)
+		So(err, ShouldBeNil)
+		So(client, ShouldNotBeNil)
+		So(atomic.LoadInt32(&calls), ShouldEqual, int32(3))
+	})
+
+	Convey("Test unauthorized requests are not retried", t, func() {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			http.Error(res, "Not Authorized", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL), WithRetry(RetryPolicy{Attempts: 3}))
+		So(client.Ping(), ShouldNotBeNil)
+		So(atomic.LoadInt32(&calls), ShouldEqual, int32(1))
+	})
+
+	Convey("Test credential errors are not retried", t, func() {
+		var calls int32
+		creds := CredentialsFunc(func() (string, string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "", "", errors.New("no secret")
+		})
+
+		client, _ := NewClient(WithURL("http://127.0.0.1:1/transmission/rpc"), WithCredentials(creds),
+			WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}))
+		So(client.Ping(), ShouldNotBeNil)
+		So(atomic.LoadInt32(&calls), ShouldEqual, int32(1))
+	})
+
+	Convey("Test transport errors are retried", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		var calls int32
+		creds := CredentialsFunc(func() (string, string, error) {
+			atomic.AddInt32(&calls, 1)
+			return "", "", nil
+		})
+		client, _ := NewClient(WithURL(url), WithCredentials(creds),
+			WithRetry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}))
+		So(client.Ping(), ShouldNotBeNil)
+		So(atomic.LoadInt32(&calls), ShouldEqual, int32(3))
+	})
+
+	Convey("Test default fields are requested", t, func() {
+		var fields []string
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			var cmd Command
+			json.NewDecoder(req.Body).Decode(&cmd)
+			fields = cmd.Arguments.Fields
+			fmt.Fprintf(res, `{"arguments":{"torrents":[]},"result":"success"}`)
+		}))
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL), WithDefaultFields("name", "status"))
+		_, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(fields, ShouldResemble, []string{"id", "name", "status"})
+	})
+}
@@ -0,0 +1,399 @@
+package transmission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+)
+
+// Protocol selects the dialect used to talk to the daemon
+type Protocol int
+
+const (
+	// ProtocolAuto picks JSON-RPC 2.0 when the daemon's rpc-version supports it
+	ProtocolAuto Protocol = iota
+	// ProtocolLegacy is the original Transmission RPC protocol
+	ProtocolLegacy
+	// ProtocolJSONRPC is the JSON-RPC 2.0 API introduced in Transmission 4.1
+	ProtocolJSONRPC
+)
+
+// jsonrpcVersion is the first rpc-version (Transmission 4.1) speaking JSON-RPC 2.0
+const jsonrpcVersion = 18
+
+// RPCError is returned when the daemon answers a request with anything but success
+type RPCError struct {
+	Method string
+	Result string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("transmission: %s: %s", e.Method, e.Result)
+}
+
+// request is a single RPC call, independent of the dialect
+type request struct {
+	Method    string
+	Arguments interface{}
+	Tag       int
+}
+
+// response is a single RPC reply; Arguments is still in the wire dialect
+// and has to go through protocol.unmarshal
+type response struct {
+	Result    string
+	Arguments json.RawMessage
+	Tag       int
+}
+
+type protocol interface {
+	encode(req request) ([]byte, error)
+	decode(data []byte) (response, error)
+	unmarshal(args json.RawMessage, out interface{}) error
+}
+
+// batchProtocol is implemented by dialects that can send several
+// requests in one round trip
+type batchProtocol interface {
+	encodeBatch(reqs []request) ([]byte, error)
+	decodeBatch(data []byte) ([]response, error)
+}
+
+func newProtocol(p Protocol) protocol {
+	if p == ProtocolJSONRPC {
+		return jsonrpcProtocol{}
+	}
+	return legacyProtocol{}
+}
+
+func (ac *TransmissionClient) nextTag() int {
+	return int(atomic.AddInt32(&ac.tags, 1))
+}
+
+// roundTrip sends a single request in the given dialect, tagging it
+// unless it already carries a tag
+func (ac *TransmissionClient) roundTrip(proto protocol, req request) (response, error) {
+	if req.Tag == 0 {
+		req.Tag = ac.nextTag()
+	}
+	body, err := proto.encode(req)
+	if err != nil {
+		return response{}, err
+	}
+	output, err := ac.apiclient.Post(string(body))
+	if err != nil {
+		return response{}, err
+	}
+	res, err := proto.decode(output)
+	if err != nil {
+		return response{}, err
+	}
+	// daemons that don't echo tags leave it at 0
+	if res.Tag != 0 && res.Tag != req.Tag {
+		return response{}, fmt.Errorf("transmission: %s: reply tag %d doesn't match request tag %d", req.Method, res.Tag, req.Tag)
+	}
+	return res, nil
+}
+
+// call sends method with args and decodes the reply's arguments into out,
+// which may be nil. Anything but a successful result is an *RPCError.
+func (ac *TransmissionClient) call(method string, args, out interface{}) error {
+	proto, err := ac.protocol()
+	if err != nil {
+		return err
+	}
+	res, err := ac.roundTrip(proto, request{Method: method, Arguments: args})
+	if err != nil {
+		return err
+	}
+	if res.Result != "success" {
+		return &RPCError{Method: method, Result: res.Result}
+	}
+	if out == nil {
+		return nil
+	}
+	return proto.unmarshal(res.Arguments, out)
+}
+
+type legacyProtocol struct{}
+
+type legacyRequest struct {
+	Method    string      `json:"method"`
+	Arguments interface{} `json:"arguments,omitempty"`
+	Tag       int         `json:"tag,omitempty"`
+}
+
+type legacyResponse struct {
+	Result    string          `json:"result"`
+	Arguments json.RawMessage `json:"arguments"`
+	Tag       int             `json:"tag"`
+}
+
+func (legacyProtocol) encode(req request) ([]byte, error) {
+	return json.Marshal(legacyRequest{Method: req.Method, Arguments: req.Arguments, Tag: req.Tag})
+}
+
+func (legacyProtocol) decode(data []byte) (response, error) {
+	var res legacyResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return response{}, err
+	}
+	return response{Result: res.Result, Arguments: res.Arguments, Tag: res.Tag}, nil
+}
+
+func (legacyProtocol) unmarshal(args json.RawMessage, out interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+	return json.Unmarshal(args, out)
+}
+
+// jsonrpcProtocol speaks JSON-RPC 2.0 with snake_case method names and keys.
+// Requests are converted from the legacy keys found in the struct tags,
+// replies are converted back using the tags of the type they decode into.
+type jsonrpcProtocol struct{}
+
+type jsonrpcRequest struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      int         `json:"id"`
+}
+
+type jsonrpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			ErrorString string `json:"error_string"`
+		} `json:"data"`
+	} `json:"error"`
+	ID int `json:"id"`
+}
+
+func (jsonrpcProtocol) encode(req request) ([]byte, error) {
+	params, err := snakeParams(req.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonrpcRequest{
+		Version: "2.0",
+		Method:  snakeCase(req.Method),
+		Params:  params,
+		ID:      req.Tag,
+	})
+}
+
+func (p jsonrpcProtocol) encodeBatch(reqs []request) ([]byte, error) {
+	batch := make([]json.RawMessage, 0, len(reqs))
+	for _, req := range reqs {
+		data, err := p.encode(req)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, data)
+	}
+	return json.Marshal(batch)
+}
+
+func (jsonrpcProtocol) decodeBatch(data []byte) ([]response, error) {
+	var batch []jsonrpcResponse
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return nil, err
+	}
+	responses := make([]response, 0, len(batch))
+	for _, res := range batch {
+		responses = append(responses, res.response())
+	}
+	return responses, nil
+}
+
+func (jsonrpcProtocol) decode(data []byte) (response, error) {
+	var res jsonrpcResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return response{}, err
+	}
+	return res.response(), nil
+}
+
+func (res jsonrpcResponse) response() response {
+	if res.Error != nil {
+		result := res.Error.Message
+		if res.Error.Data.ErrorString != "" {
+			result += ": " + res.Error.Data.ErrorString
+		}
+		return response{Result: result, Tag: res.ID}
+	}
+	return response{Result: "success", Arguments: res.Result, Tag: res.ID}
+}
+
+func (jsonrpcProtocol) unmarshal(args json.RawMessage, out interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(legacyValue(v, reflect.TypeOf(out)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// snakeParams converts the keys of args, and the names listed in "fields", to snake_case
+func snakeParams(args interface{}) (interface{}, error) {
+	if args == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if m, ok := v.(map[string]interface{}); ok {
+		if fields, ok := m["fields"].([]interface{}); ok {
+			for i, f := range fields {
+				if name, ok := f.(string); ok {
+					fields[i] = snakeCase(name)
+				}
+			}
+		}
+	}
+	return snakeKeys(v), nil
+}
+
+// snakeKeys returns v with every object key converted to snake_case
+func snakeKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[snakeCase(k)] = snakeKeys(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = snakeKeys(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// snakeCase turns legacy names like "downloadDir" and "delete-local-data"
+// into "download_dir" and "delete_local_data"
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '-':
+			b.WriteRune('_')
+		case unicode.IsUpper(r):
+			if i > 0 && s[i-1] != '-' && s[i-1] != '_' {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// structKey is a struct field as seen by the legacy protocol
+type structKey struct {
+	name string
+	typ  reflect.Type
+}
+
+var structKeyCache sync.Map // reflect.Type -> map[string]structKey
+
+// structKeys maps the snake_case form of every json key of struct type t
+// to the key used in its tags
+func structKeys(t reflect.Type) map[string]structKey {
+	if keys, ok := structKeyCache.Load(t); ok {
+		return keys.(map[string]structKey)
+	}
+	keys := make(map[string]structKey)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && derefType(f.Type).Kind() == reflect.Struct {
+			// fields of embedded structs are promoted
+			for k, v := range structKeys(derefType(f.Type)) {
+				keys[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		keys[snakeCase(name)] = structKey{name: name, typ: f.Type}
+	}
+	structKeyCache.Store(t, keys)
+	return keys
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// legacyValue renames the keys of the decoded JSON value v to the ones
+// expected by the Go type t it's going to be unmarshaled into
+func legacyValue(v interface{}, t reflect.Type) interface{} {
+	t = derefType(t)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			switch t.Kind() {
+			case reflect.Struct:
+				if key, ok := structKeys(t)[k]; ok {
+					m[key.name] = legacyValue(val, key.typ)
+					continue
+				}
+			case reflect.Map:
+				m[k] = legacyValue(val, t.Elem())
+				continue
+			}
+			m[k] = val
+		}
+		return m
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i := range v {
+				v[i] = legacyValue(v[i], t.Elem())
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,93 @@
+package transmission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// jsonrpcServer answers legacy session-gets with rpcVersion and JSON-RPC 2.0
+// requests with reply, recording the last JSON-RPC request it saw
+func jsonrpcServer(rpcVersion int, reply string, last *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&body)
+		if body["jsonrpc"] == nil {
+			fmt.Fprintf(res, `{"arguments":{"rpc-version":%d,"torrents":[{"id":1,"downloadDir":"/legacy"}]},"result":"success","tag":%v}`,
+				rpcVersion, body["tag"])
+			return
+		}
+		*last = body
+		fmt.Fprintf(res, `{"jsonrpc":"2.0","result":%s,"id":%v}`, reply, body["id"])
+	}))
+}
+
+func TestProtocolDetection(t *testing.T) {
+	Convey("Test JSON-RPC 2.0 is used when the daemon supports it", t, func() {
+		var last map[string]interface{}
+		server := jsonrpcServer(18, `{"torrents":[{"id":5,"name":"Test","download_dir":"/data","hash_string":"abc"}]}`, &last)
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		torrents, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(len(torrents), ShouldEqual, 1)
+		So(torrents[0].ID, ShouldEqual, 5)
+		So(torrents[0].DownloadDir, ShouldEqual, "/data")
+		So(torrents[0].HashString, ShouldEqual, "abc")
+
+		So(last["method"], ShouldEqual, "torrent_get")
+		fields := last["params"].(map[string]interface{})["fields"].([]interface{})
+		So(fields, ShouldContain, "download_dir")
+		So(fields, ShouldContain, "upload_ratio")
+	})
+
+	Convey("Test older daemons keep the legacy protocol", t, func() {
+		var last map[string]interface{}
+		server := jsonrpcServer(17, `{}`, &last)
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		torrents, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(torrents[0].DownloadDir, ShouldEqual, "/legacy")
+		So(last, ShouldBeNil)
+	})
+
+	Convey("Test forcing a protocol skips detection", t, func() {
+		var last map[string]interface{}
+		server := jsonrpcServer(17, `{"torrents":[]}`, &last)
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL), WithProtocol(ProtocolJSONRPC))
+		_, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(last["method"], ShouldEqual, "torrent_get")
+	})
+
+	Convey("Test JSON-RPC errors become RPC errors", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(res, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`)
+		}))
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL), WithProtocol(ProtocolJSONRPC))
+		err := client.call("torrent-frobnicate", nil, nil)
+		So(err, ShouldNotBeNil)
+		So(err.(*RPCError).Result, ShouldEqual, "Method not found")
+	})
+}
+
+func TestSnakeCase(t *testing.T) {
+	Convey("Test legacy names are converted to snake_case", t, func() {
+		So(snakeCase("downloadDir"), ShouldEqual, "download_dir")
+		So(snakeCase("delete-local-data"), ShouldEqual, "delete_local_data")
+		So(snakeCase("torrent-get"), ShouldEqual, "torrent_get")
+		So(snakeCase("rpc-version"), ShouldEqual, "rpc_version")
+		So(snakeCase("id"), ShouldEqual, "id")
+	})
+}
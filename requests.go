@@ -0,0 +1,121 @@
+package transmission
+
+import "encoding/json"
+
+// Call sends req as method and decodes the reply's arguments into a Resp.
+// It returns an *RPCError if the daemon reports anything but success.
+// The request and response types below cover the methods this package
+// uses; use struct{} for methods without arguments or a reply.
+func Call[Req, Resp any](ac *TransmissionClient, method string, req Req) (Resp, error) {
+	var resp Resp
+	err := ac.call(method, req, &resp)
+	return resp, err
+}
+
+// TorrentGetRequest is the request of torrent-get
+type TorrentGetRequest struct {
+	Fields []string `json:"fields"`
+	IDs    []int    `json:"ids,omitempty"` // nil means every torrent
+	// RecentlyActive asks for the torrents that changed recently instead
+	// of IDs, the reply lists the removed ones too
+	RecentlyActive bool `json:"-"`
+}
+
+// MarshalJSON sends RecentlyActive the way the daemon expects it
+func (r TorrentGetRequest) MarshalJSON() ([]byte, error) {
+	type plain TorrentGetRequest
+	if !r.RecentlyActive {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		IDs string `json:"ids"`
+	}{plain(r), "recently-active"})
+}
+
+// TorrentGetResponse is the reply to torrent-get
+type TorrentGetResponse struct {
+	Torrents Torrents `json:"torrents"`
+	Removed  []int    `json:"removed,omitempty"`
+}
+
+// TorrentAddRequest is the request of torrent-add. Set either Filename, a
+// URL, magnet link or path on the daemon's host, or MetaInfo, the base64
+// encoded torrent file. The NewAddCmd functions make one.
+type TorrentAddRequest struct {
+	Filename    string   `json:"filename,omitempty"`
+	MetaInfo    string   `json:"metainfo,omitempty"`
+	DownloadDir string   `json:"download-dir,omitempty"`
+	Paused      bool     `json:"paused,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// TorrentAddResponse is the reply to torrent-add, only one of the fields is set
+type TorrentAddResponse struct {
+	TorrentAdded     *TorrentAdded `json:"torrent-added,omitempty"`
+	TorrentDuplicate *TorrentAdded `json:"torrent-duplicate,omitempty"`
+}
+
+// TorrentActionRequest is the request of torrent-start, torrent-stop,
+// torrent-verify and the other methods acting on a list of torrents
+type TorrentActionRequest struct {
+	IDs []int `json:"ids,omitempty"` // nil means every torrent
+}
+
+// TorrentRemoveRequest is the request of torrent-remove
+type TorrentRemoveRequest struct {
+	IDs             []int `json:"ids"`
+	DeleteLocalData bool  `json:"delete-local-data,omitempty"`
+}
+
+// TorrentSetRequest is the request of torrent-set; nil fields are left alone
+type TorrentSetRequest struct {
+	IDs         []int     `json:"ids"`
+	Group       *string   `json:"group,omitempty"`
+	Labels      *[]string `json:"labels,omitempty"`
+	TrackerList *string   `json:"trackerList,omitempty"`
+}
+
+// TorrentSetLocationRequest is the request of torrent-set-location
+type TorrentSetLocationRequest struct {
+	IDs      []int  `json:"ids"`
+	Location string `json:"location"`
+	Move     bool   `json:"move"`
+}
+
+// TorrentRenamePathRequest is the request of torrent-rename-path
+type TorrentRenamePathRequest struct {
+	IDs  []int  `json:"ids"`
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+// FreeSpaceRequest is the request of free-space, the reply is a DiskSpace
+type FreeSpaceRequest struct {
+	Path string `json:"path"`
+}
+
+// PortTestRequest is the request of port-test
+type PortTestRequest struct {
+	IPProtocol IPProtocol `json:"ipProtocol,omitempty"`
+}
+
+// PortTestResponse is the reply to port-test
+type PortTestResponse struct {
+	PortIsOpen bool `json:"port-is-open"`
+}
+
+// GroupGetRequest is the request of group-get
+type GroupGetRequest struct {
+	Group []string `json:"group,omitempty"` // nil means every group
+}
+
+// GroupGetResponse is the reply to group-get
+type GroupGetResponse struct {
+	Group []BandwidthGroup `json:"group"`
+}
+
+// BlocklistUpdateResponse is the reply to blocklist-update
+type BlocklistUpdateResponse struct {
+	BlocklistSize int `json:"blocklist-size"`
+}
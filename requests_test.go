@@ -0,0 +1,93 @@
+package transmission
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCall(t *testing.T) {
+	Convey("Test Call sends the request and decodes the reply", t, func() {
+		server, requests := rpcServer(17, map[string]string{
+			"torrent-get": `{"torrents":[{"id":3,"name":"Test"}],"removed":[4]}`,
+		})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		out, err := Call[TorrentGetRequest, TorrentGetResponse](client, "torrent-get",
+			TorrentGetRequest{Fields: []string{"id", "name"}, RecentlyActive: true})
+		So(err, ShouldBeNil)
+		So(len(out.Torrents), ShouldEqual, 1)
+		So(out.Torrents[0].Name, ShouldEqual, "Test")
+		So(out.Removed, ShouldResemble, []int{4})
+
+		reqs := requests()
+		So(len(reqs), ShouldEqual, 1)
+		So(reqs[0].Arguments["ids"], ShouldEqual, "recently-active")
+		So(reqs[0].Arguments["fields"], ShouldResemble, []interface{}{"id", "name"})
+	})
+
+	Convey("Test requests only send the fields of their method", t, func() {
+		server, requests := rpcServer(17, nil)
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		So(client.SetTrackers(2, [][]string{{"http://a"}, {"http://b"}}), ShouldBeNil)
+		result, err := client.StartTorrent(2)
+		So(err, ShouldBeNil)
+		So(result, ShouldEqual, "success")
+
+		reqs := requests()
+		So(len(reqs), ShouldEqual, 2)
+		So(reqs[0].Arguments, ShouldResemble, map[string]interface{}{
+			"ids":         []interface{}{float64(2)},
+			"trackerList": "http://a\n\nhttp://b",
+		})
+		So(reqs[1].Arguments, ShouldResemble, map[string]interface{}{
+			"ids": []interface{}{float64(2)},
+		})
+	})
+
+	Convey("Test adding only sends the torrent-add arguments", t, func() {
+		server, requests := rpcServer(17, map[string]string{
+			"torrent-add": `{"torrent-added":{"id":5,"name":"Sintel"}}`,
+		})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		cmd := NewAddCmdByURL("magnet:?xt=urn:btih:abc")
+		cmd.SetPaused(true)
+		torrent, err := client.ExecuteAddCommand(cmd)
+		So(err, ShouldBeNil)
+		So(torrent.ID, ShouldEqual, 5)
+
+		reqs := requests()
+		So(reqs[0].Arguments, ShouldResemble, map[string]interface{}{
+			"filename": "magnet:?xt=urn:btih:abc",
+			"paused":   true,
+		})
+	})
+
+	Convey("Test adding a torrent the daemon already has", t, func() {
+		server, _ := rpcServer(17, map[string]string{
+			"torrent-add": `{"torrent-duplicate":{"id":3,"name":"Sintel"}}`,
+		})
+		defer server.Close()
+
+		client, _ := NewClient(WithURL(server.URL))
+		torrent, err := client.ExecuteAddCommand(NewAddCmdByURL("magnet:?xt=urn:btih:abc"))
+		var dup *DuplicateTorrentError
+		So(errors.As(err, &dup), ShouldBeTrue)
+		So(dup.Torrent.ID, ShouldEqual, 3)
+		So(torrent.Name, ShouldEqual, "Sintel")
+	})
+
+	Convey("Test a Command only sends torrent-get arguments", t, func() {
+		b, err := json.Marshal(NewGetTorrentsCmd())
+		So(err, ShouldBeNil)
+		So(string(b), ShouldNotContainSubstring, "torrent-added")
+		So(string(b), ShouldNotContainSubstring, "version")
+	})
+}
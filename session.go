@@ -0,0 +1,129 @@
+package transmission
+
+// Session holds the daemon's settings as returned by session-get.
+// Speed limits are in KB/s.
+type Session struct {
+	AltSpeedDown            int        `json:"alt-speed-down"`
+	AltSpeedEnabled         bool       `json:"alt-speed-enabled"`
+	AltSpeedTimeBegin       int        `json:"alt-speed-time-begin"`
+	AltSpeedTimeDay         Weekdays   `json:"alt-speed-time-day"`
+	AltSpeedTimeEnabled     bool       `json:"alt-speed-time-enabled"`
+	AltSpeedTimeEnd         int        `json:"alt-speed-time-end"`
+	AltSpeedUp              int        `json:"alt-speed-up"`
+	BlocklistEnabled        bool       `json:"blocklist-enabled"`
+	BlocklistSize           int        `json:"blocklist-size"`
+	BlocklistURL            string     `json:"blocklist-url"`
+	ConfigDir               string     `json:"config-dir"`
+	DHTEnabled              bool       `json:"dht-enabled"`
+	DownloadDir             string     `json:"download-dir"`
+	DownloadQueueEnabled    bool       `json:"download-queue-enabled"`
+	DownloadQueueSize       int        `json:"download-queue-size"`
+	Encryption              Encryption `json:"encryption"`
+	IdleSeedingLimit        int        `json:"idle-seeding-limit"`
+	IdleSeedingLimitEnabled bool       `json:"idle-seeding-limit-enabled"`
+	IncompleteDir           string     `json:"incomplete-dir"`
+	IncompleteDirEnabled    bool       `json:"incomplete-dir-enabled"`
+	LPDEnabled              bool       `json:"lpd-enabled"`
+	PeerLimitGlobal         int        `json:"peer-limit-global"`
+	PeerLimitPerTorrent     int        `json:"peer-limit-per-torrent"`
+	PeerPort                int        `json:"peer-port"`
+	PeerPortRandomOnStart   bool       `json:"peer-port-random-on-start"`
+	PEXEnabled              bool       `json:"pex-enabled"`
+	PortForwardingEnabled   bool       `json:"port-forwarding-enabled"`
+	RenamePartialFiles      bool       `json:"rename-partial-files"`
+	RPCVersion              int        `json:"rpc-version"`
+	RPCVersionMinimum       int        `json:"rpc-version-minimum"`
+	SeedQueueEnabled        bool       `json:"seed-queue-enabled"`
+	SeedQueueSize           int        `json:"seed-queue-size"`
+	SeedRatioLimit          float64    `json:"seedRatioLimit"`
+	SeedRatioLimited        bool       `json:"seedRatioLimited"`
+	SpeedLimitDown          int        `json:"speed-limit-down"`
+	SpeedLimitDownEnabled   bool       `json:"speed-limit-down-enabled"`
+	SpeedLimitUp            int        `json:"speed-limit-up"`
+	SpeedLimitUpEnabled     bool       `json:"speed-limit-up-enabled"`
+	StartAddedTorrents      bool       `json:"start-added-torrents"`
+	Version                 string     `json:"version"`
+}
+
+// SessionSettings holds the settings to change with SetSession; nil fields
+// are left alone. Bool, Int, Float and String help filling it in.
+type SessionSettings struct {
+	AltSpeedDown            *int        `json:"alt-speed-down,omitempty"`
+	AltSpeedEnabled         *bool       `json:"alt-speed-enabled,omitempty"`
+	AltSpeedTimeBegin       *int        `json:"alt-speed-time-begin,omitempty"`
+	AltSpeedTimeDay         *Weekdays   `json:"alt-speed-time-day,omitempty"`
+	AltSpeedTimeEnabled     *bool       `json:"alt-speed-time-enabled,omitempty"`
+	AltSpeedTimeEnd         *int        `json:"alt-speed-time-end,omitempty"`
+	AltSpeedUp              *int        `json:"alt-speed-up,omitempty"`
+	BlocklistEnabled        *bool       `json:"blocklist-enabled,omitempty"`
+	BlocklistURL            *string     `json:"blocklist-url,omitempty"`
+	DHTEnabled              *bool       `json:"dht-enabled,omitempty"`
+	DownloadDir             *string     `json:"download-dir,omitempty"`
+	DownloadQueueEnabled    *bool       `json:"download-queue-enabled,omitempty"`
+	DownloadQueueSize       *int        `json:"download-queue-size,omitempty"`
+	Encryption              *Encryption `json:"encryption,omitempty"`
+	IdleSeedingLimit        *int        `json:"idle-seeding-limit,omitempty"`
+	IdleSeedingLimitEnabled *bool       `json:"idle-seeding-limit-enabled,omitempty"`
+	IncompleteDir           *string     `json:"incomplete-dir,omitempty"`
+	IncompleteDirEnabled    *bool       `json:"incomplete-dir-enabled,omitempty"`
+	LPDEnabled              *bool       `json:"lpd-enabled,omitempty"`
+	PeerLimitGlobal         *int        `json:"peer-limit-global,omitempty"`
+	PeerLimitPerTorrent     *int        `json:"peer-limit-per-torrent,omitempty"`
+	PeerPort                *int        `json:"peer-port,omitempty"`
+	PeerPortRandomOnStart   *bool       `json:"peer-port-random-on-start,omitempty"`
+	PEXEnabled              *bool       `json:"pex-enabled,omitempty"`
+	PortForwardingEnabled   *bool       `json:"port-forwarding-enabled,omitempty"`
+	RenamePartialFiles      *bool       `json:"rename-partial-files,omitempty"`
+	SeedQueueEnabled        *bool       `json:"seed-queue-enabled,omitempty"`
+	SeedQueueSize           *int        `json:"seed-queue-size,omitempty"`
+	SeedRatioLimit          *float64    `json:"seedRatioLimit,omitempty"`
+	SeedRatioLimited        *bool       `json:"seedRatioLimited,omitempty"`
+	SpeedLimitDown          *int        `json:"speed-limit-down,omitempty"`
+	SpeedLimitDownEnabled   *bool       `json:"speed-limit-down-enabled,omitempty"`
+	SpeedLimitUp            *int        `json:"speed-limit-up,omitempty"`
+	SpeedLimitUpEnabled     *bool       `json:"speed-limit-up-enabled,omitempty"`
+	StartAddedTorrents      *bool       `json:"start-added-torrents,omitempty"`
+}
+
+// Bool returns a pointer to v, for SessionSettings
+func Bool(v bool) *bool { return &v }
+
+// Int returns a pointer to v, for SessionSettings
+func Int(v int) *int { return &v }
+
+// Float returns a pointer to v, for SessionSettings
+func Float(v float64) *float64 { return &v }
+
+// String returns a pointer to v, for SessionSettings
+func String(v string) *string { return &v }
+
+// GetSession returns the daemon's settings
+func (ac *TransmissionClient) GetSession() (*Session, error) {
+	session, err := Call[struct{}, Session](ac, "session-get", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// SetSession changes the daemon's settings
+func (ac *TransmissionClient) SetSession(settings SessionSettings) error {
+	_, err := Call[SessionSettings, struct{}](ac, "session-set", settings)
+	return err
+}
+
+// BlocklistUpdate makes the daemon download the blocklist from the
+// session's blocklist-url and returns the number of rules in the new list
+func (ac *TransmissionClient) BlocklistUpdate() (int, error) {
+	out, err := Call[struct{}, BlocklistUpdateResponse](ac, "blocklist-update", struct{}{})
+	if err != nil {
+		return 0, err
+	}
+	return out.BlocklistSize, nil
+}
+
+// SessionClose shuts the daemon down
+func (ac *TransmissionClient) SessionClose() error {
+	_, err := Call[struct{}, struct{}](ac, "session-close", struct{}{})
+	return err
+}
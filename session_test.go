@@ -0,0 +1,58 @@
+package transmission
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSession(t *testing.T) {
+	server, requests := rpcServer(17, map[string]string{
+		"session-get": `{"rpc-version":17,"version":"4.0.5","download-dir":"/downloads",
+  "blocklist-enabled":true,"blocklist-url":"http://example.org/list.gz","blocklist-size":10,
+  "peer-port":51413,"seedRatioLimit":2.5,"seedRatioLimited":true}`,
+		"blocklist-update": `{"blocklist-size":12345}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	Convey("Test getting the session", t, func() {
+		session, err := client.GetSession()
+		So(err, ShouldBeNil)
+		So(session.Version, ShouldEqual, "4.0.5")
+		So(session.DownloadDir, ShouldEqual, "/downloads")
+		So(session.BlocklistEnabled, ShouldBeTrue)
+		So(session.BlocklistURL, ShouldEqual, "http://example.org/list.gz")
+		So(session.PeerPort, ShouldEqual, 51413)
+		So(session.SeedRatioLimit, ShouldEqual, 2.5)
+	})
+
+	Convey("Test only set settings are sent", t, func() {
+		err := client.SetSession(SessionSettings{
+			BlocklistEnabled: Bool(false),
+			BlocklistURL:     String("http://example.org/other.gz"),
+		})
+		So(err, ShouldBeNil)
+
+		reqs := requests()
+		last := reqs[len(reqs)-1]
+		So(last.Method, ShouldEqual, "session-set")
+		So(last.Arguments, ShouldResemble, map[string]interface{}{
+			"blocklist-enabled": false,
+			"blocklist-url":     "http://example.org/other.gz",
+		})
+	})
+
+	Convey("Test updating the blocklist", t, func() {
+		size, err := client.BlocklistUpdate()
+		So(err, ShouldBeNil)
+		So(size, ShouldEqual, 12345)
+	})
+
+	Convey("Test closing the session", t, func() {
+		So(client.SessionClose(), ShouldBeNil)
+
+		reqs := requests()
+		So(reqs[len(reqs)-1].Method, ShouldEqual, "session-close")
+	})
+}
@@ -1,6 +1,13 @@
 package transmission
 
-import "sort"
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 type Sorting int
 
@@ -27,136 +34,284 @@ const (
 	SortRevRatio
 )
 
-// sorting types
-type (
-	byID         Torrents
-	byName       Torrents
-	byAge        Torrents
-	bySize       Torrents
-	byProgress   Torrents
-	byDownSpeed  Torrents
-	byUpSpeed    Torrents
-	byDownloaded Torrents
-	byUploaded   Torrents
-	byRatio      Torrents
+// SortField is a torrent field to sort by
+type SortField int
+
+const (
+	ByID SortField = iota
+	ByName
+	ByStatus
+	ByAge
+	BySize
+	ByLeftUntilDone
+	ByProgress
+	ByETA
+	ByRatio
+	ByDownSpeed
+	ByUpSpeed
+	ByDownloaded
+	ByUploaded
+	ByHave
+	ByDownloadDir
+	ByHash
+	ByFinished
+	BySeedRatioMode
+	ByTracker
+	ByGroup
+	ByLabels
+	ByError
+	ByQueuePosition
+	ByActivity
+	ByDone
+	ByStarted
+	ByStalled
+	ByMetadata
+	BySeedIdleMode
 )
 
-func (t byID) Len() int           { return len(t) }
-func (t byID) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byID) Less(i, j int) bool { return t[i].ID < t[j].ID }
+// SortKey is one level of a multi-key sort
+type SortKey struct {
+	Field SortField
+	Desc  bool
+}
 
-func (t byName) Len() int           { return len(t) }
-func (t byName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byName) Less(i, j int) bool { return t[i].Name < t[j].Name }
+// Asc sorts by f, smallest first
+func (f SortField) Asc() SortKey { return SortKey{Field: f} }
 
-func (t byAge) Len() int           { return len(t) }
-func (t byAge) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byAge) Less(i, j int) bool { return t[i].AddedDate < t[j].AddedDate }
+// Desc sorts by f, largest first
+func (f SortField) Desc() SortKey { return SortKey{Field: f, Desc: true} }
 
-func (t bySize) Len() int           { return len(t) }
-func (t bySize) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t bySize) Less(i, j int) bool { return t[i].SizeWhenDone < t[j].SizeWhenDone }
+// sortings maps the single key Sorting values to their SortKey
+var sortings = map[Sorting]SortKey{
+	SortID:            ByID.Asc(),
+	SortRevID:         ByID.Desc(),
+	SortName:          ByName.Asc(),
+	SortRevName:       ByName.Desc(),
+	SortAge:           ByAge.Asc(),
+	SortRevAge:        ByAge.Desc(),
+	SortSize:          BySize.Asc(),
+	SortRevSize:       BySize.Desc(),
+	SortProgress:      ByProgress.Asc(),
+	SortRevProgress:   ByProgress.Desc(),
+	SortDownSpeed:     ByDownSpeed.Asc(),
+	SortRevDownSpeed:  ByDownSpeed.Desc(),
+	SortUpSpeed:       ByUpSpeed.Asc(),
+	SortRevUpSpeed:    ByUpSpeed.Desc(),
+	SortDownloaded:    ByDownloaded.Asc(),
+	SortRevDownloaded: ByDownloaded.Desc(),
+	SortUploaded:      ByUploaded.Asc(),
+	SortRevUploaded:   ByUploaded.Desc(),
+	SortRatio:         ByRatio.Asc(),
+	SortRevRatio:      ByRatio.Desc(),
+}
 
-func (t byProgress) Len() int           { return len(t) }
-func (t byProgress) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byProgress) Less(i, j int) bool { return t[i].PercentDone < t[j].PercentDone }
+// Key returns the SortKey equivalent to s
+func (s Sorting) Key() SortKey {
+	return sortings[s]
+}
 
-func (t byDownSpeed) Len() int           { return len(t) }
-func (t byDownSpeed) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byDownSpeed) Less(i, j int) bool { return t[i].RateDownload < t[j].RateDownload }
+// comparators compare two torrents by a field, returning <0, 0 or >0
+var comparators = map[SortField]func(a, b *Torrent) int{
+	ByID:            func(a, b *Torrent) int { return compareInt(int64(a.ID), int64(b.ID)) },
+	ByName:          func(a, b *Torrent) int { return compareNatural(a.Name, b.Name) },
+	ByStatus:        func(a, b *Torrent) int { return compareInt(int64(a.Status), int64(b.Status)) },
+	ByAge:           func(a, b *Torrent) int { return compareTime(a.AddedDate, b.AddedDate) },
+	BySize:          func(a, b *Torrent) int { return compareUint(a.SizeWhenDone, b.SizeWhenDone) },
+	ByLeftUntilDone: func(a, b *Torrent) int { return compareUint(a.LeftUntilDone, b.LeftUntilDone) },
+	ByProgress:      func(a, b *Torrent) int { return compareFloat64(a.PercentDone, b.PercentDone) },
+	ByETA:           func(a, b *Torrent) int { return compareFloat64(etaKey(a), etaKey(b)) },
+	ByRatio:         func(a, b *Torrent) int { return compareFloat64(ratioKey(a), ratioKey(b)) },
+	ByDownSpeed:     func(a, b *Torrent) int { return compareUint(a.RateDownload, b.RateDownload) },
+	ByUpSpeed:       func(a, b *Torrent) int { return compareUint(a.RateUpload, b.RateUpload) },
+	ByDownloaded:    func(a, b *Torrent) int { return compareUint(a.DownloadedEver, b.DownloadedEver) },
+	ByUploaded:      func(a, b *Torrent) int { return compareUint(a.UploadedEver, b.UploadedEver) },
+	ByHave:          func(a, b *Torrent) int { return compareUint(a.Have(), b.Have()) },
+	ByDownloadDir:   func(a, b *Torrent) int { return compareNatural(a.DownloadDir, b.DownloadDir) },
+	ByHash:          func(a, b *Torrent) int { return strings.Compare(a.HashString, b.HashString) },
+	ByFinished:      func(a, b *Torrent) int { return compareBool(a.IsFinished, b.IsFinished) },
+	BySeedRatioMode: func(a, b *Torrent) int { return compareInt(int64(a.SeedRatioMode), int64(b.SeedRatioMode)) },
+	ByTracker:       func(a, b *Torrent) int { return compareNatural(firstTracker(a), firstTracker(b)) },
+	ByGroup:         func(a, b *Torrent) int { return compareNatural(a.Group, b.Group) },
+	ByLabels: func(a, b *Torrent) int {
+		return compareNatural(strings.Join(a.Labels, ","), strings.Join(b.Labels, ","))
+	},
+	ByError: func(a, b *Torrent) int {
+		if c := compareInt(int64(a.Error), int64(b.Error)); c != 0 {
+			return c
+		}
+		return compareNatural(a.ErrorString, b.ErrorString)
+	},
+	ByQueuePosition: func(a, b *Torrent) int { return compareInt(int64(a.QueuePosition), int64(b.QueuePosition)) },
+	ByActivity:      func(a, b *Torrent) int { return compareTime(a.ActivityDate, b.ActivityDate) },
+	ByDone:          func(a, b *Torrent) int { return compareTime(a.DoneDate, b.DoneDate) },
+	ByStarted:       func(a, b *Torrent) int { return compareTime(a.StartDate, b.StartDate) },
+	ByStalled:       func(a, b *Torrent) int { return compareBool(a.IsStalled, b.IsStalled) },
+	ByMetadata: func(a, b *Torrent) int {
+		return compareFloat64(a.MetadataPercentComplete, b.MetadataPercentComplete)
+	},
+	BySeedIdleMode: func(a, b *Torrent) int { return compareInt(int64(a.SeedIdleMode), int64(b.SeedIdleMode)) },
+}
 
-func (t byUpSpeed) Len() int           { return len(t) }
-func (t byUpSpeed) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byUpSpeed) Less(i, j int) bool { return t[i].RateUpload < t[j].RateUpload }
+// SortBy sorts the torrents by keys, the first key deciding and each
+// following one breaking ties of the previous. Torrents equal on every
+// key keep their order.
+func (t Torrents) SortBy(keys ...SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.SliceStable(t, func(i, j int) bool {
+		for _, key := range keys {
+			compare, ok := comparators[key.Field]
+			if !ok {
+				continue
+			}
+			c := compare(t[i], t[j])
+			if key.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
 
-func (t byDownloaded) Len() int           { return len(t) }
-func (t byDownloaded) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byDownloaded) Less(i, j int) bool { return t[i].DownloadedEver < t[j].DownloadedEver }
+func (t Torrents) sortBy(f SortField, reverse bool) {
+	t.SortBy(SortKey{Field: f, Desc: reverse})
+}
 
-func (t byUploaded) Len() int           { return len(t) }
-func (t byUploaded) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byUploaded) Less(i, j int) bool { return t[i].UploadedEver < t[j].UploadedEver }
+func (t Torrents) SortID(reverse bool)         { t.sortBy(ByID, reverse) }
+func (t Torrents) SortName(reverse bool)       { t.sortBy(ByName, reverse) }
+func (t Torrents) SortAge(reverse bool)        { t.sortBy(ByAge, reverse) }
+func (t Torrents) SortSize(reverse bool)       { t.sortBy(BySize, reverse) }
+func (t Torrents) SortProgress(reverse bool)   { t.sortBy(ByProgress, reverse) }
+func (t Torrents) SortDownSpeed(reverse bool)  { t.sortBy(ByDownSpeed, reverse) }
+func (t Torrents) SortUpSpeed(reverse bool)    { t.sortBy(ByUpSpeed, reverse) }
+func (t Torrents) SortDownloaded(reverse bool) { t.sortBy(ByDownloaded, reverse) }
+func (t Torrents) SortUploaded(reverse bool)   { t.sortBy(ByUploaded, reverse) }
+func (t Torrents) SortRatio(reverse bool)      { t.sortBy(ByRatio, reverse) }
 
-func (t byRatio) Len() int           { return len(t) }
-func (t byRatio) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t byRatio) Less(i, j int) bool { return t[i].UploadRatio < t[j].UploadRatio }
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
 
-func (t Torrents) SortID(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byID(t)))
-		return
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
 	}
-	sort.Sort(byID(t))
+	return 0
 }
 
-func (t Torrents) SortName(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byName(t)))
-		return
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
 	}
-	sort.Sort(byName(t))
+	return 0
 }
 
-func (t Torrents) SortAge(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byAge(t)))
-		return
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
 	}
-	sort.Sort(byAge(t))
+	return 0
 }
 
-func (t Torrents) SortSize(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(bySize(t)))
-		return
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
 	}
-	sort.Sort(bySize(t))
+	return 1
 }
 
-func (t Torrents) SortProgress(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byProgress(t)))
-		return
+// etaKey puts unknown ETAs after every known one
+func etaKey(t *Torrent) float64 {
+	if t.Eta < 0 {
+		return math.Inf(1)
 	}
-	sort.Sort(byProgress(t))
+	return float64(t.Eta)
 }
 
-func (t Torrents) SortDownSpeed(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byDownSpeed(t)))
-		return
+// ratioKey orders the "not available" ratio (-1) first and the infinite
+// one (-2) last
+func ratioKey(t *Torrent) float64 {
+	if t.UploadRatio == -2 {
+		return math.Inf(1)
 	}
-	sort.Sort(byDownSpeed(t))
+	return t.UploadRatio
 }
 
-func (t Torrents) SortUpSpeed(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byUpSpeed(t)))
-		return
+func firstTracker(t *Torrent) string {
+	if len(t.Trackers) == 0 {
+		return ""
 	}
-	sort.Sort(byUpSpeed(t))
+	return t.Trackers[0].Announce
 }
 
-func (t Torrents) SortDownloaded(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byDownloaded(t)))
-		return
+// compareNatural compares strings ignoring case and ordering runs of
+// digits by their value, so "Episode 9" comes before "Episode 10"
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		ra, sa := utf8.DecodeRuneInString(a)
+		rb, sb := utf8.DecodeRuneInString(b)
+
+		if isDigit(ra) && isDigit(rb) {
+			da, db := digitRun(a), digitRun(b)
+			if c := compareDigits(da, db); c != 0 {
+				return c
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+
+		la, lb := unicode.ToLower(ra), unicode.ToLower(rb)
+		if la != lb {
+			return compareInt(int64(la), int64(lb))
+		}
+		a, b = a[sa:], b[sb:]
 	}
-	sort.Sort(byDownloaded(t))
+	return compareInt(int64(len(a)), int64(len(b)))
 }
 
-func (t Torrents) SortUploaded(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byUploaded(t)))
-		return
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func digitRun(s string) string {
+	i := 0
+	for i < len(s) && isDigit(rune(s[i])) {
+		i++
 	}
-	sort.Sort(byUploaded(t))
+	return s[:i]
 }
 
-func (t Torrents) SortRatio(reverse bool) {
-	if reverse {
-		sort.Sort(sort.Reverse(byRatio(t)))
-		return
+// compareDigits compares two runs of digits by value, then by length so
+// "01" comes after "1"
+func compareDigits(a, b string) int {
+	ta, tb := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if c := compareInt(int64(len(ta)), int64(len(tb))); c != 0 {
+		return c
+	}
+	if c := strings.Compare(ta, tb); c != 0 {
+		return c
 	}
-	sort.Sort(byRatio(t))
+	return compareInt(int64(len(a)), int64(len(b)))
 }
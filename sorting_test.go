@@ -0,0 +1,123 @@
+package transmission
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func names(t Torrents) []string {
+	n := make([]string, 0, len(t))
+	for i := range t {
+		n = append(n, t[i].Name)
+	}
+	return n
+}
+
+func TestSortBy(t *testing.T) {
+	Convey("Test names sort naturally, ignoring case", t, func() {
+		torrents := Torrents{
+			{ID: 1, Name: "episode 10"},
+			{ID: 2, Name: "Episode 9"},
+			{ID: 3, Name: "episode 010"},
+			{ID: 4, Name: "Alpha"},
+			{ID: 5, Name: "episode 9b"},
+		}
+		torrents.SortBy(ByName.Asc())
+		So(names(torrents), ShouldResemble, []string{"Alpha", "Episode 9", "episode 9b", "episode 10", "episode 010"})
+
+		torrents.SortBy(ByName.Desc())
+		So(names(torrents), ShouldResemble, []string{"episode 010", "episode 10", "episode 9b", "Episode 9", "Alpha"})
+	})
+
+	Convey("Test sorting by several keys", t, func() {
+		torrents := Torrents{
+			{ID: 1, Name: "b", Status: StatusSeeding, UploadRatio: 1},
+			{ID: 2, Name: "a", Status: StatusStopped, UploadRatio: 5},
+			{ID: 3, Name: "c", Status: StatusSeeding, UploadRatio: 2},
+			{ID: 4, Name: "a", Status: StatusSeeding, UploadRatio: 1},
+		}
+		torrents.SortBy(ByStatus.Asc(), ByRatio.Desc(), ByName.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 3, 4, 1})
+	})
+
+	Convey("Test ties keep their order", t, func() {
+		torrents := Torrents{{ID: 3}, {ID: 1}, {ID: 2}}
+		torrents.SortBy(ByStatus.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{3, 1, 2})
+	})
+
+	Convey("Test unknown ETAs and ratios", t, func() {
+		torrents := Torrents{{ID: 1, Eta: EtaUnknown}, {ID: 2, Eta: time.Minute}, {ID: 3, Eta: 5 * time.Second}}
+		torrents.SortBy(ByETA.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{3, 2, 1})
+
+		torrents = Torrents{{ID: 1, UploadRatio: -2}, {ID: 2, UploadRatio: -1}, {ID: 3, UploadRatio: 3}}
+		torrents.SortBy(ByRatio.Desc())
+		So(torrents.GetIDs(), ShouldResemble, []int{1, 3, 2})
+	})
+
+	Convey("Test queue position and activity", t, func() {
+		torrents := Torrents{{ID: 1, QueuePosition: 2, ActivityDate: time.Unix(100, 0)}, {ID: 2, QueuePosition: 0, ActivityDate: time.Unix(300, 0)}, {ID: 3, QueuePosition: 1}}
+		torrents.SortBy(ByQueuePosition.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 3, 1})
+		torrents.SortBy(ByActivity.Desc())
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 1, 3})
+	})
+
+	Convey("Test dates, stalled, metadata and idle mode", t, func() {
+		torrents := Torrents{
+			{ID: 1, DoneDate: time.Unix(200, 0), StartDate: time.Unix(50, 0), IsStalled: true, MetadataPercentComplete: 1, SeedIdleMode: IdleUnlimited},
+			{ID: 2, StartDate: time.Unix(150, 0), MetadataPercentComplete: 0.5, SeedIdleMode: IdleSingle},
+			{ID: 3, DoneDate: time.Unix(100, 0), StartDate: time.Unix(100, 0), IsStalled: true},
+		}
+		torrents.SortBy(ByDone.Desc())
+		So(torrents.GetIDs(), ShouldResemble, []int{1, 3, 2})
+		torrents.SortBy(ByStarted.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{1, 3, 2})
+		torrents.SortBy(ByStalled.Asc(), ByID.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 1, 3})
+		torrents.SortBy(ByMetadata.Desc())
+		So(torrents.GetIDs(), ShouldResemble, []int{1, 2, 3})
+		torrents.SortBy(BySeedIdleMode.Asc())
+		So(torrents.GetIDs(), ShouldResemble, []int{3, 2, 1})
+	})
+
+	Convey("Test every field has a comparator", t, func() {
+		for f := ByID; f <= BySeedIdleMode; f++ {
+			_, ok := comparators[f]
+			So(ok, ShouldBeTrue)
+		}
+	})
+
+	Convey("Test the legacy single key sorts", t, func() {
+		torrents := Torrents{{ID: 1, SizeWhenDone: 5}, {ID: 2, SizeWhenDone: 10}, {ID: 3, SizeWhenDone: 1}}
+		torrents.SortSize(true)
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 1, 3})
+		torrents.SortID(false)
+		So(torrents.GetIDs(), ShouldResemble, []int{1, 2, 3})
+	})
+}
+
+func TestClientSorting(t *testing.T) {
+	server, _ := rpcServer(17, map[string]string{
+		"torrent-get": `{"torrents":[{"id":1,"name":"b","uploadRatio":1},{"id":2,"name":"a","uploadRatio":3},{"id":3,"name":"c","uploadRatio":2}]}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	Convey("Test GetTorrents applies the client's sorting", t, func() {
+		torrents, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(torrents.GetIDs(), ShouldResemble, []int{1, 2, 3})
+
+		client.SetSort(SortName)
+		torrents, _ = client.GetTorrents()
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 1, 3})
+
+		client.SetSortKeys(ByRatio.Desc())
+		torrents, _ = client.GetTorrents()
+		So(torrents.GetIDs(), ShouldResemble, []int{2, 3, 1})
+	})
+}
@@ -0,0 +1,99 @@
+package transmission
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// DiskSpace is the space on the filesystem holding Path, in bytes.
+// Total is only reported by Transmission 4 and later.
+type DiskSpace struct {
+	Path  string `json:"path"`
+	Free  uint64 `json:"size-bytes"`
+	Total uint64 `json:"total_size"`
+}
+
+// IPProtocol selects the address family for PortTest
+type IPProtocol string
+
+const (
+	IPAny IPProtocol = ""
+	IPv4  IPProtocol = "ipv4"
+	IPv6  IPProtocol = "ipv6"
+)
+
+// InsufficientSpaceError is returned by CheckFreeSpace when a torrent doesn't fit
+type InsufficientSpaceError struct {
+	Dir  string
+	Need uint64
+	Free uint64
+}
+
+func (e *InsufficientSpaceError) Error() string {
+	return fmt.Sprintf("transmission: %s needs %d bytes but only %d are free", e.Dir, e.Need, e.Free)
+}
+
+// GetFreeSpace returns the space available at path on the daemon's host
+func (ac *TransmissionClient) GetFreeSpace(path string) (*DiskSpace, error) {
+	if err := ac.require(FeatureFreeSpace); err != nil {
+		return nil, err
+	}
+	space, err := Call[FreeSpaceRequest, DiskSpace](ac, "free-space", FreeSpaceRequest{Path: path})
+	if err != nil {
+		return nil, err
+	}
+	return &space, nil
+}
+
+// PortTest asks the daemon whether its peer port is reachable from the
+// internet. Choosing IPv4 or IPv6 needs Transmission 4.1.
+func (ac *TransmissionClient) PortTest(proto IPProtocol) (bool, error) {
+	if proto != IPAny {
+		if err := ac.require(FeaturePortTestIPProtocol); err != nil {
+			return false, err
+		}
+	}
+	out, err := Call[PortTestRequest, PortTestResponse](ac, "port-test", PortTestRequest{IPProtocol: proto})
+	if err != nil {
+		return false, err
+	}
+	return out.PortIsOpen, nil
+}
+
+// CheckFreeSpace checks that the torrent added by cmd fits in its download
+// directory, or the session's default one, returning an
+// *InsufficientSpaceError if it doesn't. Only commands made by
+// NewAddCmdByFile can be checked, the size of a torrent added by URL or
+// magnet link isn't known until the daemon fetches it.
+func (ac *TransmissionClient) CheckFreeSpace(cmd *TorrentAddRequest) error {
+	if cmd.MetaInfo == "" {
+		return errors.New("transmission: torrent size is only known for commands made by NewAddCmdByFile")
+	}
+	data, err := base64.StdEncoding.DecodeString(cmd.MetaInfo)
+	if err != nil {
+		return err
+	}
+	mi, err := ParseMetaInfo(data)
+	if err != nil {
+		return err
+	}
+
+	dir := cmd.DownloadDir
+	if dir == "" {
+		session, err := ac.GetSession()
+		if err != nil {
+			return err
+		}
+		dir = session.DownloadDir
+	}
+
+	space, err := ac.GetFreeSpace(dir)
+	if err != nil {
+		return err
+	}
+	if space.Free < mi.TotalSize {
+		return &InsufficientSpaceError{Dir: dir, Need: mi.TotalSize, Free: space.Free}
+	}
+	return nil
+}
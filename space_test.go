@@ -0,0 +1,82 @@
+package transmission
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const multiFileTorrent = "d8:announce23:http://tracker/announce4:infod5:filesld6:lengthi1000e4:pathl3:cd15:a.txteed6:lengthi2000e4:pathl5:b.txteee4:name4:test12:piece lengthi16384eee"
+
+func TestParseMetaInfo(t *testing.T) {
+	Convey("Test parsing a multi file torrent", t, func() {
+		mi, err := ParseMetaInfo([]byte(multiFileTorrent))
+		So(err, ShouldBeNil)
+		So(mi.Name, ShouldEqual, "test")
+		So(mi.TotalSize, ShouldEqual, uint64(3000))
+		So(mi.Files, ShouldResemble, []MetaInfoFile{
+			{Path: []string{"cd1", "a.txt"}, Length: 1000},
+			{Path: []string{"b.txt"}, Length: 2000},
+		})
+	})
+
+	Convey("Test parsing a single file torrent", t, func() {
+		mi, err := ParseMetaInfo([]byte("d4:infod6:lengthi42e4:name5:a.isoee"))
+		So(err, ShouldBeNil)
+		So(mi.TotalSize, ShouldEqual, uint64(42))
+		So(mi.Files[0].Path, ShouldResemble, []string{"a.iso"})
+	})
+
+	Convey("Test malformed metainfo", t, func() {
+		for _, data := range []string{"", "d4:info", "d4:infoi1ee", "li1e", "d4:infod4:name1:xee", "d3:abc99:xe",
+			"9223372036854775807:abc", "d4:infod6:lengthi-1e4:name1:xee",
+			"d4:infod5:filesld6:lengthi-5e4:pathl1:xeee4:name1:xee"} {
+			_, err := ParseMetaInfo([]byte(data))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestFreeSpace(t *testing.T) {
+	server, requests := rpcServer(17, map[string]string{
+		"session-get": `{"rpc-version":17,"download-dir":"/downloads"}`,
+		"free-space":  `{"path":"/downloads","size-bytes":5000,"total_size":10000}`,
+		"port-test":   `{"port-is-open":true}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	Convey("Test getting free space", t, func() {
+		space, err := client.GetFreeSpace("/downloads")
+		So(err, ShouldBeNil)
+		So(*space, ShouldResemble, DiskSpace{Path: "/downloads", Free: 5000, Total: 10000})
+	})
+
+	Convey("Test testing the port", t, func() {
+		open, err := client.PortTest(IPAny)
+		So(err, ShouldBeNil)
+		So(open, ShouldBeTrue)
+
+		_, err = client.PortTest(IPv6)
+		So(errors.Is(err, ErrUnsupported), ShouldBeTrue)
+	})
+
+	Convey("Test checking space before adding", t, func() {
+		cmd := NewAddCmd()
+		cmd.MetaInfo = base64.StdEncoding.EncodeToString([]byte(multiFileTorrent))
+		So(client.CheckFreeSpace(cmd), ShouldBeNil)
+
+		reqs := requests()
+		So(reqs[len(reqs)-1].Arguments["path"], ShouldEqual, "/downloads")
+
+		cmd.SetDownloadDir("/small")
+		cmd.MetaInfo = base64.StdEncoding.EncodeToString([]byte("d4:infod6:lengthi6000e4:name5:a.isoee"))
+		err := client.CheckFreeSpace(cmd)
+		So(err, ShouldNotBeNil)
+		So(*err.(*InsufficientSpaceError), ShouldResemble, InsufficientSpaceError{Dir: "/small", Need: 6000, Free: 5000})
+
+		So(client.CheckFreeSpace(NewAddCmdByURL("magnet:?xt=urn:btih:abc")), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,41 @@
+package transmission
+
+import "time"
+
+// Stats is the reply to session-stats
+type Stats struct {
+	ActiveTorrentCount int         `json:"activeTorrentCount"`
+	CumulativeStats    StatsTotals `json:"cumulative-stats"`
+	CurrentStats       StatsTotals `json:"current-stats"`
+	DownloadSpeed      uint64      `json:"downloadSpeed"`
+	PausedTorrentCount int         `json:"pausedTorrentCount"`
+	TorrentCount       int         `json:"torrentCount"`
+	UploadSpeed        uint64      `json:"uploadSpeed"`
+}
+
+// StatsTotals are the totals over all sessions (CumulativeStats) or since
+// the daemon started (CurrentStats)
+type StatsTotals struct {
+	DownloadedBytes uint64        `json:"downloadedBytes"`
+	FilesAdded      int           `json:"filesAdded"`
+	SecondsActive   time.Duration `json:"secondsActive"`
+	SessionCount    int           `json:"sessionCount"`
+	UploadedBytes   uint64        `json:"uploadedBytes"`
+}
+
+func (s *Stats) CurrentActiveTime() string {
+	return s.CurrentStats.SecondsActive.String()
+}
+
+func (s *Stats) CumulativeActiveTime() string {
+	return s.CumulativeStats.SecondsActive.String()
+}
+
+// GetStats returns "session-stats"
+func (ac *TransmissionClient) GetStats() (*Stats, error) {
+	stats, err := Call[struct{}, Stats](ac, "session-stats", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,98 @@
+package transmission
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Sentinel values of Torrent.Eta
+const (
+	EtaNotAvailable = -1 * time.Second // the torrent isn't downloading or seeding to a ratio
+	EtaUnknown      = -2 * time.Second // not enough data to estimate
+)
+
+// The daemon sends durations in seconds and dates as Unix timestamps, with
+// 0 for dates that haven't happened yet
+
+func fromUnix(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func fromSeconds(sec int64) time.Duration {
+	return time.Duration(sec) * time.Second
+}
+
+func toSeconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+type torrentAlias Torrent
+
+// torrentJSON is a Torrent as the daemon sends it
+type torrentJSON struct {
+	*torrentAlias
+	Eta          int64 `json:"eta"`
+	AddedDate    int64 `json:"addedDate"`
+	DoneDate     int64 `json:"doneDate"`
+	ActivityDate int64 `json:"activityDate"`
+	StartDate    int64 `json:"startDate"`
+}
+
+// UnmarshalJSON converts the daemon's seconds and Unix timestamps
+func (t *Torrent) UnmarshalJSON(data []byte) error {
+	aux := torrentJSON{torrentAlias: (*torrentAlias)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	t.Eta = fromSeconds(aux.Eta)
+	t.AddedDate = fromUnix(aux.AddedDate)
+	t.DoneDate = fromUnix(aux.DoneDate)
+	t.ActivityDate = fromUnix(aux.ActivityDate)
+	t.StartDate = fromUnix(aux.StartDate)
+	return nil
+}
+
+// MarshalJSON writes the torrent the way the daemon sends it
+func (t Torrent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(torrentJSON{
+		torrentAlias: (*torrentAlias)(&t),
+		Eta:          toSeconds(t.Eta),
+		AddedDate:    toUnix(t.AddedDate),
+		DoneDate:     toUnix(t.DoneDate),
+		ActivityDate: toUnix(t.ActivityDate),
+		StartDate:    toUnix(t.StartDate),
+	})
+}
+
+type statsAlias StatsTotals
+
+// statsJSON is StatsTotals as the daemon sends it
+type statsJSON struct {
+	*statsAlias
+	SecondsActive int64 `json:"secondsActive"`
+}
+
+// UnmarshalJSON converts the daemon's seconds
+func (s *StatsTotals) UnmarshalJSON(data []byte) error {
+	aux := statsJSON{statsAlias: (*statsAlias)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.SecondsActive = fromSeconds(aux.SecondsActive)
+	return nil
+}
+
+// MarshalJSON writes the stats the way the daemon sends them
+func (s StatsTotals) MarshalJSON() ([]byte, error) {
+	return json.Marshal(statsJSON{statsAlias: (*statsAlias)(&s), SecondsActive: toSeconds(s.SecondsActive)})
+}
@@ -0,0 +1,73 @@
+package transmission
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTorrentTimes(t *testing.T) {
+	Convey("Test decoding durations and dates", t, func() {
+		var torrent Torrent
+		err := json.Unmarshal([]byte(`{"id":1,"eta":3600,"addedDate":1700000000,"doneDate":0,"activityDate":1700000600,"startDate":1700000100}`), &torrent)
+		So(err, ShouldBeNil)
+		So(torrent.ID, ShouldEqual, 1)
+		So(torrent.Eta, ShouldEqual, time.Hour)
+		So(torrent.ETA(), ShouldEqual, "1h")
+		So(torrent.AddedDate.Equal(time.Unix(1700000000, 0)), ShouldBeTrue)
+		So(torrent.DoneDate.IsZero(), ShouldBeTrue)
+		So(torrent.ActivityDate.Sub(torrent.AddedDate), ShouldEqual, 10*time.Minute)
+		So(torrent.StartDate.Unix(), ShouldEqual, int64(1700000100))
+	})
+
+	Convey("Test ETA sentinels", t, func() {
+		var torrents Torrents
+		So(json.Unmarshal([]byte(`[{"eta":-1},{"eta":-2}]`), &torrents), ShouldBeNil)
+		So(torrents[0].Eta, ShouldEqual, EtaNotAvailable)
+		So(torrents[1].Eta, ShouldEqual, EtaUnknown)
+		So(torrents[1].ETA(), ShouldEqual, "∞")
+	})
+
+	Convey("Test torrents round-trip", t, func() {
+		in := Torrent{ID: 2, Name: "Sintel", Eta: EtaUnknown, AddedDate: time.Unix(1700000000, 0)}
+		data, err := json.Marshal(in)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, `"eta":-2`)
+		So(string(data), ShouldContainSubstring, `"addedDate":1700000000`)
+		So(string(data), ShouldContainSubstring, `"doneDate":0`)
+
+		var out Torrent
+		So(json.Unmarshal(data, &out), ShouldBeNil)
+		So(out.Name, ShouldEqual, "Sintel")
+		So(out.Eta, ShouldEqual, EtaUnknown)
+		So(out.AddedDate.Equal(in.AddedDate), ShouldBeTrue)
+		So(out.DoneDate.IsZero(), ShouldBeTrue)
+	})
+
+	Convey("Test session-stats active time", t, func() {
+		server, _ := rpcServer(17, map[string]string{
+			"session-stats": `{"torrentCount":1,"cumulative-stats":{"secondsActive":90061},"current-stats":{"secondsActive":3600}}`,
+		})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		stats, err := client.GetStats()
+		So(err, ShouldBeNil)
+		So(stats.CumulativeStats.SecondsActive, ShouldEqual, 25*time.Hour+time.Minute+time.Second)
+		So(stats.CurrentActiveTime(), ShouldEqual, "1h0m0s")
+	})
+
+	Convey("Test dates over JSON-RPC", t, func() {
+		var last map[string]interface{}
+		server := jsonrpcServer(18, `{"torrents":[{"id":5,"added_date":1700000000,"eta":120}]}`, &last)
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		torrents, err := client.GetTorrents()
+		So(err, ShouldBeNil)
+		So(torrents[0].AddedDate.Unix(), ShouldEqual, int64(1700000000))
+		So(torrents[0].Eta, ShouldEqual, 2*time.Minute)
+	})
+}
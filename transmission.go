@@ -3,54 +3,44 @@ package transmission
 import (
 	"bytes"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"strings"
+	"sync"
 	"time"
-)
 
-const (
-	StatusStopped = iota
-	StatusCheckPending
-	StatusChecking
-	StatusDownloadPending
-	StatusDownloading
-	StatusSeedPending
-	StatusSeeding
+	"github.com/tubbebubbe/transmission/format"
 )
 
 //TransmissionClient to talk to transmission
 type TransmissionClient struct {
 	apiclient *ApiClient
+
+	fields []string // torrent fields to request, nil means NewGetTorrentsCmd's
+	tags   int32    // last tag handed out by nextTag
+
+	mu        sync.RWMutex
+	sortKeys  []SortKey     // which sorting GetTorrents applies
+	protoMode Protocol      // requested dialect
+	proto     protocol      // dialect in use
+	caps      *Capabilities // nil until connected
 }
 
 type Command struct {
 	Method    string    `json:"method,omitempty"`
 	Arguments arguments `json:"arguments,omitempty"`
 	Result    string    `json:"result,omitempty"`
+	Tag       int       `json:"tag,omitempty"`
 }
 
+// arguments are those of torrent-get, which is what Command is left for.
+// The other methods have their own types in requests.go, which Batch.Add
+// takes too.
 type arguments struct {
-	Fields       []string     `json:"fields,omitempty"`
-	Torrents     Torrents     `json:"torrents,omitempty"`
-	Ids          []int        `json:"ids,omitempty"`
-	DeleteData   bool         `json:"delete-local-data,omitempty"`
-	DownloadDir  string       `json:"download-dir,omitempty"`
-	MetaInfo     string       `json:"metainfo,omitempty"`
-	Filename     string       `json:"filename,omitempty"`
-	TorrentAdded TorrentAdded `json:"torrent-added"`
-	Location     string       `json:"location,omitempty"`
-	Move         bool         `json:"move,omitempty"`
-	// Stats
-	ActiveTorrentCount int             `json:"activeTorrentCount"`
-	CumulativeStats    cumulativeStats `json:"cumulative-stats"`
-	CurrentStats       currentStats    `json:"current-stats"`
-	DownloadSpeed      uint64          `json:"downloadSpeed"`
-	PausedTorrentCount int             `json:"pausedTorrentCount"`
-	TorrentCount       int             `json:"torrentCount"`
-	UploadSpeed        uint64          `json:"uploadSpeed"`
-	Version            string          `json:"version"`
+	Fields   []string `json:"fields,omitempty"`
+	Torrents Torrents `json:"torrents,omitempty"`
+	Ids      []int    `json:"ids,omitempty"`
 }
 
 type tracker struct {
@@ -60,6 +50,16 @@ type tracker struct {
 	Tire     int    `json:"tire"`
 }
 
+// DuplicateTorrentError is returned by ExecuteAddCommand when the daemon
+// already has the torrent
+type DuplicateTorrentError struct {
+	Torrent TorrentAdded
+}
+
+func (e *DuplicateTorrentError) Error() string {
+	return fmt.Sprintf("transmission: torrent already added as %d %q", e.Torrent.ID, e.Torrent.Name)
+}
+
 //TorrentAdded data returning
 type TorrentAdded struct {
 	HashString string `json:"hashString"`
@@ -67,63 +67,45 @@ type TorrentAdded struct {
 	Name       string `json:"name"`
 }
 
-// session-stats
-type Stats struct {
-	ActiveTorrentCount int
-	CumulativeStats    cumulativeStats
-	CurrentStats       currentStats
-	DownloadSpeed      uint64
-	PausedTorrentCount int
-	TorrentCount       int
-	UploadSpeed        uint64
-}
-type cumulativeStats struct {
-	DownloadedBytes uint64        `json:"downloadedBytes"`
-	FilesAdded      int           `json:"filesAdded"`
-	SecondsActive   time.Duration `json:"secondsActive"`
-	SessionCount    int           `json:"sessionCount"`
-	UploadedBytes   uint64        `json:"uploadedBytes"`
-}
-type currentStats struct {
-	DownloadedBytes uint64        `json:"downloadedBytes"`
-	FilesAdded      int           `json:"filesAdded"`
-	SecondsActive   time.Duration `json:"secondsActive"`
-	SessionCount    int           `json:"sessionCount"`
-	UploadedBytes   uint64        `json:"uploadedBytes"`
-}
-
-func (s *Stats) CurrentActiveTime() string {
-	return (time.Second * s.CurrentStats.SecondsActive).String()
-}
-
-func (s *Stats) CumulativeActiveTime() string {
-	return (time.Second * s.CumulativeStats.SecondsActive).String()
-}
-
 //Torrent struct for torrents
 type Torrent struct {
-	ID             int           `json:"id"`
-	Name           string        `json:"name"`
-	Status         int           `json:"status"`
-	AddedDate      int64         `json:"addedDate"`
-	LeftUntilDone  uint64        `json:"leftUntilDone"`
-	SizeWhenDone   uint64        `json:"sizeWhenDone"`
-	Eta            time.Duration `json:"eta"`
-	UploadRatio    float64       `json:"uploadRatio"`
-	RateDownload   uint64        `json:"rateDownload"`
-	RateUpload     uint64        `json:"rateUpload"`
-	DownloadDir    string        `json:"downloadDir"`
-	DownloadedEver uint64        `json:"downloadedEver"`
-	UploadedEver   uint64        `json:"uploadedEver"`
-	HashString     string        `json:"hashString"`
-	HaveUnchecked  uint64        `json:"haveUnchecked"`
-	HaveValid      uint64        `json:"haveValid"`
-	IsFinished     bool          `json:"isFinished"`
-	PercentDone    float64       `json:"percentDone"`
-	SeedRatioMode  int           `json:"seedRatioMode"`
-	Trackers       []tracker     `json:"trackers"`
-	Error          int           `json:"error"`
-	ErrorString    string        `json:"errorString"`
+	ID                      int           `json:"id"`
+	Name                    string        `json:"name"`
+	Status                  Status        `json:"status"`
+	AddedDate               time.Time     `json:"addedDate"`
+	DoneDate                time.Time     `json:"doneDate"`
+	StartDate               time.Time     `json:"startDate"`
+	LeftUntilDone           uint64        `json:"leftUntilDone"`
+	SizeWhenDone            uint64        `json:"sizeWhenDone"`
+	Eta                     time.Duration `json:"eta"`
+	UploadRatio             float64       `json:"uploadRatio"`
+	RateDownload            uint64        `json:"rateDownload"`
+	RateUpload              uint64        `json:"rateUpload"`
+	DownloadDir             string        `json:"downloadDir"`
+	DownloadedEver          uint64        `json:"downloadedEver"`
+	UploadedEver            uint64        `json:"uploadedEver"`
+	HashString              string        `json:"hashString"`
+	HaveUnchecked           uint64        `json:"haveUnchecked"`
+	HaveValid               uint64        `json:"haveValid"`
+	IsFinished              bool          `json:"isFinished"`
+	IsStalled               bool          `json:"isStalled"`
+	MetadataPercentComplete float64       `json:"metadataPercentComplete"`
+	PercentDone             float64       `json:"percentDone"`
+	SeedRatioMode           RatioMode     `json:"seedRatioMode"`
+	SeedIdleMode            IdleMode      `json:"seedIdleMode"`
+	Trackers                []tracker     `json:"trackers"`
+	TrackerList             string        `json:"trackerList"`
+	Group                   string        `json:"group"`
+	Labels                  []string      `json:"labels"`
+	Error                   ErrorType     `json:"error"`
+	ErrorString             string        `json:"errorString"`
+	QueuePosition           int           `json:"queuePosition"`
+	ActivityDate            time.Time     `json:"activityDate"`
+	// Only filled in by GetTorrentDetails
+	Files        []TorrentFile `json:"files,omitempty"`
+	FileStats    []FileStat    `json:"fileStats,omitempty"`
+	Peers        []Peer        `json:"peers,omitempty"`
+	TrackerStats []TrackerStat `json:"trackerStats,omitempty"`
 }
 
 // Status translates the status of the torrent
@@ -151,17 +133,14 @@ func (t *Torrent) TorrentStatus() string {
 // Ratio returns the upload ratio of the torrent
 func (t *Torrent) Ratio() string {
 	if t.UploadRatio < 0 {
-		return "∞"
+		return format.Ratio(t.UploadRatio)
 	}
 	return fmt.Sprintf("%.3f", t.UploadRatio)
 }
 
 // ETA returns the time left for the download to finish
 func (t *Torrent) ETA() string {
-	if t.Eta < 0 {
-		return "∞"
-	}
-	return (time.Second * t.Eta).String()
+	return format.ETA(t.Eta)
 }
 
 // GetTrackers combines the torrent's trackers in one string
@@ -191,100 +170,112 @@ func (t Torrents) GetIDs() []int {
 	return ids
 }
 
-// sortType keeps track of which sorting we are using
-var sortType = SortID // SortID is transmission's default
-
-// SetSort takes a 'Sorting' to set 'sortType'
+// SetSort takes a 'Sorting' to set the sorting used by GetTorrents
 func (ac *TransmissionClient) SetSort(st Sorting) {
-	sortType = st
+	if st == SortID {
+		// transmission already returns torrents by ID
+		ac.SetSortKeys()
+		return
+	}
+	ac.SetSortKeys(st.Key())
+}
+
+// SetSortKeys sets the keys GetTorrents sorts by, see Torrents.SortBy
+func (ac *TransmissionClient) SetSortKeys(keys ...SortKey) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.sortKeys = keys
+}
+
+func (ac *TransmissionClient) sorting() []SortKey {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.sortKeys
 }
 
 //New create new transmission torrent
 func New(url string, username string, password string) (*TransmissionClient, error) {
-	apiclient := NewClient(url, username, password)
-	client := &TransmissionClient{apiclient: apiclient}
+	client, err := NewClient(WithURL(url), WithAuth(username, password))
+	if err != nil {
+		return nil, err
+	}
 
 	// test that we have a working client
-	cmd := Command{Method: "session-get"}
-	_, err := client.sendCommand(cmd)
-	if err != nil {
-		return client, err
+	return client, client.Ping()
+}
+
+// Ping checks that the daemon can be reached with the client's credentials
+func (ac *TransmissionClient) Ping() error {
+	if ac.connected() {
+		return ac.call("session-get", nil, nil)
 	}
+	// connecting is a session-get of its own
+	_, err := ac.protocol()
+	return err
+}
 
-	return client, nil
+// torrentFields returns the client's default fields, NewGetTorrentsCmd's
+// unless set with WithDefaultFields, plus extra, leaving out those the
+// daemon doesn't know
+func (ac *TransmissionClient) torrentFields(extra ...string) ([]string, error) {
+	caps, err := ac.Capabilities()
+	if err != nil {
+		return nil, err
+	}
+	fields := ac.fields
+	if fields == nil {
+		fields = NewGetTorrentsCmd().Arguments.Fields
+	}
+	fields = append([]string(nil), fields...)
+	for _, f := range extra {
+		if !containsString(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return caps.filterFields(fields), nil
+}
 
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
 }
 
 //GetTorrents get a list of torrents
 func (ac *TransmissionClient) GetTorrents() (Torrents, error) {
-	cmd := NewGetTorrentsCmd()
-
-	out, err := ac.ExecuteCommand(cmd)
+	fields, err := ac.torrentFields()
 	if err != nil {
 		return nil, err
 	}
 
-	torrents := out.Arguments.Torrents
-
-	// sorting
-	switch sortType {
-	case SortID:
-		return torrents, nil // already sorted by ID
-	case SortRevID:
-		torrents.SortID(true)
-	case SortName:
-		torrents.SortName(false)
-	case SortRevName:
-		torrents.SortName(true)
-	case SortAge:
-		torrents.SortAge(false)
-	case SortRevAge:
-		torrents.SortAge(true)
-	case SortSize:
-		torrents.SortSize(false)
-	case SortRevSize:
-		torrents.SortSize(true)
-	case SortProgress:
-		torrents.SortProgress(false)
-	case SortRevProgress:
-		torrents.SortProgress(true)
-	case SortDownSpeed:
-		torrents.SortDownSpeed(false)
-	case SortRevDownSpeed:
-		torrents.SortDownSpeed(true)
-	case SortUpSpeed:
-		torrents.SortUpSpeed(false)
-	case SortRevUpSpeed:
-		torrents.SortUpSpeed(true)
-	case SortDownloaded:
-		torrents.SortDownloaded(false)
-	case SortRevDownloaded:
-		torrents.SortDownloaded(true)
-	case SortUploaded:
-		torrents.SortUploaded(false)
-	case SortRevUploaded:
-		torrents.SortUploaded(true)
-	case SortRatio:
-		torrents.SortRatio(false)
-	case SortRevRatio:
-		torrents.SortRatio(true)
+	out, err := Call[TorrentGetRequest, TorrentGetResponse](ac, "torrent-get", TorrentGetRequest{Fields: fields})
+	if err != nil {
+		return nil, err
 	}
 
+	torrents := out.Torrents
+	torrents.SortBy(ac.sorting()...)
+
 	return torrents, nil
 }
 
 // GetTorrent takes an id and returns *Torrent
 func (ac *TransmissionClient) GetTorrent(id int) (*Torrent, error) {
-	cmd := NewGetTorrentsCmd()
-	cmd.Arguments.Ids = append(cmd.Arguments.Ids, id)
+	fields, err := ac.torrentFields()
+	if err != nil {
+		return &Torrent{}, err
+	}
 
-	out, err := ac.ExecuteCommand(cmd)
+	out, err := Call[TorrentGetRequest, TorrentGetResponse](ac, "torrent-get", TorrentGetRequest{Fields: fields, IDs: []int{id}})
 	if err != nil {
 		return &Torrent{}, err
 	}
 
-	if len(out.Arguments.Torrents) > 0 {
-		return out.Arguments.Torrents[0], nil
+	if len(out.Torrents) > 0 {
+		return out.Torrents[0], nil
 	}
 	return &Torrent{}, errors.New("No torrent with that id")
 }
@@ -297,38 +288,14 @@ func (ac *TransmissionClient) DeleteTorrent(id int, wd bool) (string, error) {
 		return "", err
 	}
 
-	cmd := newDelCmd(id, wd)
-
-	_, err = ac.ExecuteCommand(cmd)
-	if err != nil {
+	req := TorrentRemoveRequest{IDs: []int{id}, DeleteLocalData: wd}
+	if _, err := Call[TorrentRemoveRequest, struct{}](ac, "torrent-remove", req); err != nil {
 		return "", err
 	}
 
 	return torrent.Name, nil
 }
 
-// GetStats returns "session-stats"
-func (ac *TransmissionClient) GetStats() (*Stats, error) {
-	cmd := &Command{
-		Method: "session-stats",
-	}
-
-	out, err := ac.ExecuteCommand(cmd)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Stats{
-		ActiveTorrentCount: out.Arguments.ActiveTorrentCount,
-		CumulativeStats:    out.Arguments.CumulativeStats,
-		CurrentStats:       out.Arguments.CurrentStats,
-		DownloadSpeed:      out.Arguments.DownloadSpeed,
-		PausedTorrentCount: out.Arguments.PausedTorrentCount,
-		TorrentCount:       out.Arguments.TorrentCount,
-		UploadSpeed:        out.Arguments.UploadSpeed,
-	}, nil
-}
-
 //StartTorrent start the torrent
 func (ac *TransmissionClient) StartTorrent(id int) (string, error) {
 	return ac.sendSimpleCommand("torrent-start", id)
@@ -344,53 +311,70 @@ func (ac *TransmissionClient) VerifyTorrent(id int) (string, error) {
 	return ac.sendSimpleCommand("torrent-verify", id)
 }
 
-// StartAll starts all the torrents
-func (ac *TransmissionClient) StartAll() error {
-	cmd := Command{Method: "torrent-start"}
-	torrents, err := ac.GetTorrents()
-	if err != nil {
+// RenameTorrentPath renames the file or directory path inside a torrent to name
+func (ac *TransmissionClient) RenameTorrentPath(id int, path, name string) error {
+	if err := ac.require(FeatureRenamePath); err != nil {
 		return err
 	}
+	req := TorrentRenamePathRequest{IDs: []int{id}, Path: path, Name: name}
+	_, err := Call[TorrentRenamePathRequest, struct{}](ac, "torrent-rename-path", req)
+	return err
+}
 
-	cmd.Arguments.Ids = torrents.GetIDs()
-	if _, err := ac.sendCommand(cmd); err != nil {
+// SetTrackers replaces a torrent's trackers, one slice of announce URLs per tier
+func (ac *TransmissionClient) SetTrackers(id int, tiers [][]string) error {
+	if err := ac.require(FeatureTrackerList); err != nil {
 		return err
 	}
+	// trackerList has one announce URL per line and tiers separated by blank lines
+	list := make([]string, 0, len(tiers))
+	for _, tier := range tiers {
+		list = append(list, strings.Join(tier, "\n"))
+	}
+	trackerList := strings.Join(list, "\n\n")
+	_, err := Call[TorrentSetRequest, struct{}](ac, "torrent-set", TorrentSetRequest{IDs: []int{id}, TrackerList: &trackerList})
+	return err
+}
 
-	return nil
+// SetTorrentLocation changes where a torrent's data is, moving the data
+// there when move is set and looking for it there otherwise
+func (ac *TransmissionClient) SetTorrentLocation(id int, location string, move bool) error {
+	req := TorrentSetLocationRequest{IDs: []int{id}, Location: location, Move: move}
+	_, err := Call[TorrentSetLocationRequest, struct{}](ac, "torrent-set-location", req)
+	return err
 }
 
-// StopAll stops all torrents
-func (ac *TransmissionClient) StopAll() error {
-	cmd := Command{Method: "torrent-stop"}
+// StartAll starts all the torrents
+func (ac *TransmissionClient) StartAll() error {
 	torrents, err := ac.GetTorrents()
 	if err != nil {
 		return err
 	}
 
-	cmd.Arguments.Ids = torrents.GetIDs()
-	if _, err := ac.sendCommand(cmd); err != nil {
+	_, err = Call[TorrentActionRequest, struct{}](ac, "torrent-start", TorrentActionRequest{IDs: torrents.GetIDs()})
+	return err
+}
+
+// StopAll stops all torrents
+func (ac *TransmissionClient) StopAll() error {
+	torrents, err := ac.GetTorrents()
+	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = Call[TorrentActionRequest, struct{}](ac, "torrent-stop", TorrentActionRequest{IDs: torrents.GetIDs()})
+	return err
 }
 
 // VerifyAll verfies all torrents
 func (ac *TransmissionClient) VerifyAll() error {
-	cmd := Command{Method: "torrent-verify"}
-
 	torrents, err := ac.GetTorrents()
 	if err != nil {
 		return err
 	}
 
-	cmd.Arguments.Ids = torrents.GetIDs()
-	if _, err := ac.sendCommand(cmd); err != nil {
-		return err
-	}
-
-	return nil
+	_, err = Call[TorrentActionRequest, struct{}](ac, "torrent-verify", TorrentActionRequest{IDs: torrents.GetIDs()})
+	return err
 }
 
 func NewGetTorrentsCmd() *Command {
@@ -399,32 +383,31 @@ func NewGetTorrentsCmd() *Command {
 	cmd.Method = "torrent-get"
 	cmd.Arguments.Fields = []string{"id", "name",
 		"status", "addedDate", "leftUntilDone", "sizeWhenDone", "eta", "uploadRatio", "uploadedEver",
-		"rateDownload", "rateUpload", "downloadDir", "hashString", "haveValid", "haveUnchecked", "isFinished", "downloadedEver",
-		"percentDone", "seedRatioMode", "error", "errorString", "trackers"}
+		"rateDownload", "rateUpload", "downloadDir", "hashString", "haveValid", "haveUnchecked", "isFinished", "isStalled", "downloadedEver",
+		"percentDone", "metadataPercentComplete", "seedRatioMode", "seedIdleMode", "error", "errorString", "trackers",
+		"trackerList", "group", "labels", "queuePosition", "activityDate", "doneDate", "startDate"}
 
 	return cmd
 }
 
-func NewAddCmd() *Command {
-	cmd := &Command{}
-	cmd.Method = "torrent-add"
-	return cmd
+func NewAddCmd() *TorrentAddRequest {
+	return &TorrentAddRequest{}
 }
 
 // URL or magnet
-func NewAddCmdByURL(url string) *Command {
+func NewAddCmdByURL(url string) *TorrentAddRequest {
 	cmd := NewAddCmd()
-	cmd.Arguments.Filename = url
+	cmd.Filename = url
 	return cmd
 }
 
-func NewAddCmdByFilename(filename string) *Command {
+func NewAddCmdByFilename(filename string) *TorrentAddRequest {
 	cmd := NewAddCmd()
-	cmd.Arguments.Filename = filename
+	cmd.Filename = filename
 	return cmd
 }
 
-func NewAddCmdByFile(file string) (*Command, error) {
+func NewAddCmdByFile(file string) (*TorrentAddRequest, error) {
 	cmd := NewAddCmd()
 
 	fileData, err := ioutil.ReadFile(file)
@@ -432,48 +415,65 @@ func NewAddCmdByFile(file string) (*Command, error) {
 		return nil, err
 	}
 
-	cmd.Arguments.MetaInfo = base64.StdEncoding.EncodeToString(fileData)
+	cmd.MetaInfo = base64.StdEncoding.EncodeToString(fileData)
 
 	return cmd, nil
 }
 
-func (cmd *Command) SetDownloadDir(dir string) {
-	cmd.Arguments.DownloadDir = dir
+func (cmd *TorrentAddRequest) SetDownloadDir(dir string) {
+	cmd.DownloadDir = dir
 }
 
-func newDelCmd(id int, removeFile bool) *Command {
-	cmd := &Command{}
-	cmd.Method = "torrent-remove"
-	cmd.Arguments.Ids = []int{id}
-	cmd.Arguments.DeleteData = removeFile
-	return cmd
+// SetPaused adds the torrent without starting it
+func (cmd *TorrentAddRequest) SetPaused(paused bool) {
+	cmd.Paused = paused
 }
 
 func (ac *TransmissionClient) ExecuteCommand(cmd *Command) (*Command, error) {
 	out := &Command{}
 
-	body, err := json.Marshal(cmd)
-	if err != nil {
-		return out, err
-	}
-	output, err := ac.apiclient.Post(string(body))
+	proto, err := ac.protocol()
 	if err != nil {
 		return out, err
 	}
-	err = json.Unmarshal(output, &out)
+	res, err := ac.roundTrip(proto, cmd.request())
 	if err != nil {
 		return out, err
 	}
 
-	return out, nil
+	return out, out.fromResponse(proto, res)
 }
 
-func (ac *TransmissionClient) ExecuteAddCommand(addCmd *Command) (TorrentAdded, error) {
-	outCmd, err := ac.ExecuteCommand(addCmd)
+func (cmd *Command) request() request {
+	return request{Method: cmd.Method, Arguments: cmd.Arguments, Tag: cmd.Tag}
+}
+
+func (cmd *Command) fromResponse(proto protocol, res response) error {
+	cmd.Result = res.Result
+	cmd.Tag = res.Tag
+	return proto.unmarshal(res.Arguments, &cmd.Arguments)
+}
+
+// ExecuteAddCommand adds the torrent. If the daemon already has it, the
+// existing torrent is returned along with a *DuplicateTorrentError.
+func (ac *TransmissionClient) ExecuteAddCommand(addCmd *TorrentAddRequest) (TorrentAdded, error) {
+	if len(addCmd.Labels) > 0 {
+		// older daemons silently drop the labels
+		if err := ac.require(FeatureAddLabels); err != nil {
+			return TorrentAdded{}, err
+		}
+	}
+	out, err := Call[TorrentAddRequest, TorrentAddResponse](ac, "torrent-add", *addCmd)
 	if err != nil {
 		return TorrentAdded{}, err
 	}
-	return outCmd.Arguments.TorrentAdded, nil
+	switch {
+	case out.TorrentAdded != nil:
+		return *out.TorrentAdded, nil
+	case out.TorrentDuplicate != nil:
+		return *out.TorrentDuplicate, &DuplicateTorrentError{Torrent: *out.TorrentDuplicate}
+	}
+	return TorrentAdded{}, errors.New("transmission: torrent-add reply has no torrent")
 }
 
 func encodeFile(file string) (string, error) {
@@ -487,32 +487,23 @@ func encodeFile(file string) (string, error) {
 
 // Version returns transmission's version
 func (ac *TransmissionClient) Version() string {
-	cmd := Command{Method: "session-get"}
-
-	resp, _ := ac.sendCommand(cmd)
-	return resp.Arguments.Version
+	session, err := ac.GetSession()
+	if err != nil {
+		return ""
+	}
+	return session.Version
 }
 
+// sendSimpleCommand calls method for the torrent id and returns the
+// daemon's result, which isn't an error here
 func (ac *TransmissionClient) sendSimpleCommand(method string, id int) (result string, err error) {
-	cmd := Command{Method: method}
-	cmd.Arguments.Ids = []int{id}
-	resp, err := ac.sendCommand(cmd)
-	return resp.Result, err
-}
-
-func (ac *TransmissionClient) sendCommand(cmd Command) (response Command, err error) {
-	var body, output []byte
-	body, err = json.Marshal(cmd)
-	if err != nil {
-		return
-	}
-	output, err = ac.apiclient.Post(string(body))
-	if err != nil {
-		return
+	_, err = Call[TorrentActionRequest, struct{}](ac, method, TorrentActionRequest{IDs: []int{id}})
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Result, nil
 	}
-	err = json.Unmarshal(output, &response)
 	if err != nil {
-		return
+		return "", err
 	}
-	return response, nil
+	return "success", nil
 }
@@ -1,8 +1,11 @@
 package transmission
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/go-martini/martini"
@@ -35,6 +38,53 @@ func tTeardown() {
 	tServer.Close()
 }
 
+// rpcRequest is a request received by a daemonServer
+type rpcRequest struct {
+	Method    string                 `json:"method"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// daemonServer pretends to be a legacy daemon with the given rpc-version,
+// answering each request with the arguments reply returns for it, "{}" if
+// none. The session-get used to connect is answered with the versions
+// unless reply has something else and is the only request not recorded.
+func daemonServer(rpcVersion int, reply func(r rpcRequest) string) (*httptest.Server, func() []rpcRequest) {
+	var (
+		mu       sync.Mutex
+		requests []rpcRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		var r rpcRequest
+		json.NewDecoder(req.Body).Decode(&r)
+
+		mu.Lock()
+		defer mu.Unlock()
+		args := reply(r)
+		if r.Method == "session-get" && args == "" {
+			fmt.Fprintf(res, `{"arguments":{"rpc-version":%d,"rpc-version-minimum":1,"version":"test"},"result":"success"}`, rpcVersion)
+			return
+		}
+		requests = append(requests, r)
+		if args == "" {
+			args = "{}"
+		}
+		fmt.Fprintf(res, `{"arguments":%s,"result":"success"}`, args)
+	}))
+	return server, func() []rpcRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]rpcRequest(nil), requests...)
+	}
+}
+
+// rpcServer is a daemonServer answering each method with its arguments in
+// replies
+func rpcServer(rpcVersion int, replies map[string]string) (*httptest.Server, func() []rpcRequest) {
+	return daemonServer(rpcVersion, func(r rpcRequest) string {
+		return replies[r.Method]
+	})
+}
+
 func TestGetTorrents(t *testing.T) {
 	tSetup(`{"arguments":{"torrents":[{"eta":-1,"id":5,
   "leftUntilDone":0,"name":"Test",
@@ -0,0 +1,116 @@
+package transmission
+
+import (
+	"context"
+	"errors"
+	"path"
+	"time"
+)
+
+// DefaultWaitInterval is how often WaitFor polls unless told otherwise
+const DefaultWaitInterval = time.Second
+
+// waitFields are the fields the built-in conditions look at
+var waitFields = []string{"id", "status", "percentDone", "metadataPercentComplete", "downloadDir"}
+
+type waitOptions struct {
+	interval time.Duration
+	progress func(*Torrent)
+}
+
+// WaitOption configures WaitFor
+type WaitOption func(*waitOptions)
+
+// WaitInterval sets how often WaitFor polls the torrent, DefaultWaitInterval
+// if not positive
+func WaitInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.interval = d
+	}
+}
+
+// WaitProgress calls fn with the torrent after every poll
+func WaitProgress(fn func(*Torrent)) WaitOption {
+	return func(o *waitOptions) {
+		o.progress = fn
+	}
+}
+
+// MetadataReady holds once a magnet link's metadata has been downloaded
+func MetadataReady() Predicate {
+	return func(t *Torrent) bool {
+		return t.MetadataPercentComplete >= 1
+	}
+}
+
+// Verified holds when the torrent is neither being nor waiting to be verified
+func Verified() Predicate {
+	return Not(StatusIn(StatusCheckPending, StatusChecking))
+}
+
+// Completed holds once all wanted data has been downloaded
+func Completed() Predicate {
+	return func(t *Torrent) bool {
+		return t.MetadataPercentComplete >= 1 && t.PercentDone >= 1
+	}
+}
+
+// StatusIs holds while the torrent has one of the given statuses
+func StatusIs(statuses ...Status) Predicate {
+	return StatusIn(statuses...)
+}
+
+// Moved holds once the torrent's data is in dir and isn't being verified
+func Moved(dir string) Predicate {
+	dir = path.Clean(dir)
+	return All(Verified(), func(t *Torrent) bool {
+		return path.Clean(t.DownloadDir) == dir
+	})
+}
+
+// WaitFor polls the torrent with the given id until cond holds and returns
+// it. It gives up when ctx is done, returning the last torrent seen along
+// with the context's error, or when the torrent is removed.
+func (ac *TransmissionClient) WaitFor(ctx context.Context, id int, cond Predicate, opts ...WaitOption) (*Torrent, error) {
+	o := waitOptions{interval: DefaultWaitInterval}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.interval <= 0 {
+		o.interval = DefaultWaitInterval
+	}
+
+	fields, err := ac.torrentFields(waitFields...)
+	if err != nil {
+		return nil, err
+	}
+	req := TorrentGetRequest{Fields: fields, IDs: []int{id}}
+
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	var last *Torrent
+	for {
+		out, err := Call[TorrentGetRequest, TorrentGetResponse](ac, "torrent-get", req)
+		if err != nil {
+			return last, err
+		}
+		if len(out.Torrents) == 0 {
+			return last, errors.New("No torrent with that id")
+		}
+
+		last = out.Torrents[0]
+		if o.progress != nil {
+			o.progress(last)
+		}
+		if cond(last) {
+			return last, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package transmission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWaitFor(t *testing.T) {
+	Convey("Test waiting for a magnet link to complete", t, func() {
+		server, _ := scriptedServer([]string{
+			`{"torrents":[{"id":7,"status":4,"metadataPercentComplete":0.5,"percentDone":0}]}`,
+			`{"torrents":[{"id":7,"status":4,"metadataPercentComplete":1,"percentDone":0.2}]}`,
+			`{"torrents":[{"id":7,"status":4,"metadataPercentComplete":1,"percentDone":0.8}]}`,
+			`{"torrents":[{"id":7,"status":6,"metadataPercentComplete":1,"percentDone":1}]}`,
+		})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		var progress []float64
+		torrent, err := client.WaitFor(context.Background(), 7, Completed(),
+			WaitInterval(time.Millisecond),
+			WaitProgress(func(t *Torrent) { progress = append(progress, t.PercentDone) }))
+		So(err, ShouldBeNil)
+		So(torrent.Status, ShouldEqual, StatusSeeding)
+		So(progress, ShouldResemble, []float64{0, 0.2, 0.8, 1})
+	})
+
+	Convey("Test built-in conditions", t, func() {
+		So(MetadataReady()(&Torrent{MetadataPercentComplete: 1}), ShouldBeTrue)
+		So(Verified()(&Torrent{Status: StatusChecking}), ShouldBeFalse)
+		So(Verified()(&Torrent{Status: StatusStopped}), ShouldBeTrue)
+		So(StatusIs(StatusStopped, StatusSeeding)(&Torrent{Status: StatusSeeding}), ShouldBeTrue)
+		So(Moved("/new/dir/")(&Torrent{DownloadDir: "/new/dir", Status: StatusSeeding}), ShouldBeTrue)
+		So(Moved("/new/dir")(&Torrent{DownloadDir: "/old/dir", Status: StatusSeeding}), ShouldBeFalse)
+	})
+
+	Convey("Test giving up when the context is done", t, func() {
+		server, _ := scriptedServer([]string{`{"torrents":[{"id":7,"status":2}]}`})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		torrent, err := client.WaitFor(ctx, 7, Verified(), WaitInterval(time.Millisecond))
+		So(err, ShouldEqual, context.DeadlineExceeded)
+		So(torrent.Status, ShouldEqual, StatusChecking)
+	})
+
+	Convey("Test a non-positive interval falls back to the default", t, func() {
+		server, _ := scriptedServer([]string{`{"torrents":[{"id":7,"status":6,"metadataPercentComplete":1,"percentDone":1}]}`})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		for _, d := range []time.Duration{0, -time.Second} {
+			torrent, err := client.WaitFor(context.Background(), 7, Completed(), WaitInterval(d))
+			So(err, ShouldBeNil)
+			So(torrent.ID, ShouldEqual, 7)
+		}
+	})
+
+	Convey("Test a removed torrent stops the wait", t, func() {
+		server, _ := scriptedServer([]string{`{"torrents":[]}`})
+		defer server.Close()
+		client, _ := NewClient(WithURL(server.URL))
+
+		_, err := client.WaitFor(context.Background(), 7, Completed(), WaitInterval(time.Millisecond))
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,205 @@
+package transmission
+
+import (
+	"context"
+	"time"
+)
+
+// EventType is the kind of change reported by a watcher
+type EventType int
+
+const (
+	EventAdded EventType = iota
+	EventRemoved
+	EventStatusChanged
+	EventCompleted
+	EventErrorRaised
+	EventErrorCleared
+	EventRatioReached
+	EventStalled
+)
+
+func (e EventType) String() string {
+	switch e {
+	case EventAdded:
+		return "Added"
+	case EventRemoved:
+		return "Removed"
+	case EventStatusChanged:
+		return "StatusChanged"
+	case EventCompleted:
+		return "Completed"
+	case EventErrorRaised:
+		return "ErrorRaised"
+	case EventErrorCleared:
+		return "ErrorCleared"
+	case EventRatioReached:
+		return "RatioReached"
+	case EventStalled:
+		return "Stalled"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change to a torrent seen between two polls. Torrent is the
+// torrent as last seen, Previous as seen the poll before; Previous is nil
+// for EventAdded and Torrent is the last known state for EventRemoved.
+type Event struct {
+	Type     EventType
+	ID       int
+	Torrent  *Torrent
+	Previous *Torrent
+}
+
+// DefaultWatchInterval is how often a watcher polls unless told otherwise
+const DefaultWatchInterval = 5 * time.Second
+
+// WatchOptions configures Watch and WatchFunc
+type WatchOptions struct {
+	// Interval between polls, DefaultWatchInterval if zero
+	Interval time.Duration
+	// RatioTarget emits EventRatioReached when a torrent's upload ratio
+	// climbs to it; zero disables the event
+	RatioTarget float64
+	// FullSyncEvery fetches every torrent on every nth poll instead of just
+	// the recently active ones, to catch anything a delta missed; 10 if zero
+	FullSyncEvery int
+	// OnError is called when a poll fails; the watcher keeps polling
+	OnError func(error)
+}
+
+// watchFields are the fields the watcher needs on top of the client's defaults
+var watchFields = []string{"id", "status", "percentDone", "error", "errorString", "uploadRatio", "isStalled"}
+
+// Watch polls the daemon and sends an Event on the returned channel for
+// every change it sees, until ctx is done. The first poll only records
+// the torrents present, it doesn't report them as added.
+func (ac *TransmissionClient) Watch(ctx context.Context, opts WatchOptions) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		ac.WatchFunc(ctx, opts, func(e Event) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return events
+}
+
+// WatchFunc is like Watch but calls fn for every event, returning once ctx
+// is done with its error
+func (ac *TransmissionClient) WatchFunc(ctx context.Context, opts WatchOptions, fn func(Event)) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+	if opts.FullSyncEvery <= 0 {
+		opts.FullSyncEvery = 10
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var known map[int]*Torrent // nil until the first successful full sync
+	for poll := 0; ; poll++ {
+		full := known == nil || poll%opts.FullSyncEvery == 0
+		torrents, removed, err := ac.poll(full)
+		switch {
+		case err != nil:
+			if opts.OnError != nil {
+				opts.OnError(err)
+			}
+		case known == nil:
+			known = make(map[int]*Torrent, len(torrents))
+			for _, t := range torrents {
+				known[t.ID] = t
+			}
+		default:
+			for _, e := range diffTorrents(known, torrents, removed, full, opts.RatioTarget) {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fn(e)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches every torrent when full is set, otherwise only those active
+// recently along with the ids of the removed ones
+func (ac *TransmissionClient) poll(full bool) (Torrents, []int, error) {
+	fields, err := ac.torrentFields(watchFields...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := TorrentGetRequest{Fields: fields, RecentlyActive: !full}
+	out, err := Call[TorrentGetRequest, TorrentGetResponse](ac, "torrent-get", req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Torrents, out.Removed, nil
+}
+
+// diffTorrents updates known with the torrents of a poll and returns the
+// events telling what changed. A full poll lists every torrent, so anything
+// missing from it was removed.
+func diffTorrents(known map[int]*Torrent, torrents Torrents, removed []int, full bool, ratioTarget float64) []Event {
+	events := make([]Event, 0)
+	seen := make(map[int]bool, len(torrents))
+
+	for _, cur := range torrents {
+		seen[cur.ID] = true
+		prev, ok := known[cur.ID]
+		known[cur.ID] = cur
+		if !ok {
+			events = append(events, Event{Type: EventAdded, ID: cur.ID, Torrent: cur})
+			continue
+		}
+
+		event := func(typ EventType) {
+			events = append(events, Event{Type: typ, ID: cur.ID, Torrent: cur, Previous: prev})
+		}
+		if cur.Status != prev.Status {
+			event(EventStatusChanged)
+		}
+		if prev.PercentDone < 1 && cur.PercentDone >= 1 {
+			event(EventCompleted)
+		}
+		if prev.Error == 0 && cur.Error != 0 {
+			event(EventErrorRaised)
+		}
+		if prev.Error != 0 && cur.Error == 0 {
+			event(EventErrorCleared)
+		}
+		if ratioTarget > 0 && ratioKey(prev) < ratioTarget && ratioKey(cur) >= ratioTarget {
+			event(EventRatioReached)
+		}
+		if !prev.IsStalled && cur.IsStalled {
+			event(EventStalled)
+		}
+	}
+
+	if full {
+		for id := range known {
+			if !seen[id] {
+				removed = append(removed, id)
+			}
+		}
+	}
+	for _, id := range removed {
+		if last, ok := known[id]; ok {
+			delete(known, id)
+			events = append(events, Event{Type: EventRemoved, ID: id, Torrent: last})
+		}
+	}
+	return events
+}
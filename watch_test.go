@@ -0,0 +1,118 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// scriptedServer is a daemonServer answering the nth request after
+// connecting with steps[n], repeating the last step once it runs out, and
+// returning the ids asked for
+func scriptedServer(steps []string) (*httptest.Server, func() []interface{}) {
+	n := 0
+	server, requests := daemonServer(17, func(r rpcRequest) string {
+		if r.Method == "session-get" {
+			return ""
+		}
+		step := steps[len(steps)-1]
+		if n < len(steps) {
+			step = steps[n]
+		}
+		n++
+		return step
+	})
+	return server, func() []interface{} {
+		var ids []interface{}
+		for _, r := range requests() {
+			ids = append(ids, r.Arguments["ids"])
+		}
+		return ids
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server, ids := scriptedServer([]string{
+		`{"torrents":[
+  {"id":1,"status":4,"percentDone":0.5},
+  {"id":2,"status":6,"percentDone":1,"uploadRatio":0.9},
+  {"id":3,"status":4,"error":2,"errorString":"tracker down"}]}`,
+		`{"torrents":[
+  {"id":1,"status":6,"percentDone":1},
+  {"id":2,"status":6,"percentDone":1,"uploadRatio":1.1},
+  {"id":4,"status":4,"percentDone":0,"isStalled":true}],
+  "removed":[3]}`,
+		`{"torrents":[],"removed":[]}`,
+	})
+	defer server.Close()
+	client, _ := NewClient(WithURL(server.URL))
+
+	Convey("Test changes between polls become events", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events := client.Watch(ctx, WatchOptions{Interval: 5 * time.Millisecond, RatioTarget: 1})
+		var got []string
+		for len(got) < 5 {
+			select {
+			case e := <-events:
+				got = append(got, fmt.Sprintf("%s %d", e.Type, e.ID))
+			case <-time.After(time.Second):
+				t.Fatalf("timed out, got %v", got)
+			}
+		}
+		So(got, ShouldResemble, []string{
+			"StatusChanged 1",
+			"Completed 1",
+			"RatioReached 2",
+			"Added 4",
+			"Removed 3",
+		})
+
+		cancel()
+		for range events {
+			// drained until the watcher closes the channel
+		}
+
+		requested := ids()
+		So(requested[0], ShouldBeNil)
+		So(requested[1], ShouldEqual, "recently-active")
+	})
+}
+
+func TestDiffTorrents(t *testing.T) {
+	Convey("Test errors, stalls and full syncs", t, func() {
+		known := map[int]*Torrent{
+			1: {ID: 1, Error: 0},
+			2: {ID: 2, Error: 3},
+			3: {ID: 3},
+		}
+		events := diffTorrents(known, Torrents{
+			{ID: 1, Error: 1},
+			{ID: 2, Error: 0, IsStalled: true},
+		}, nil, true, 0)
+
+		types := make([]string, 0)
+		for _, e := range events {
+			types = append(types, fmt.Sprintf("%s %d", e.Type, e.ID))
+		}
+		So(types, ShouldResemble, []string{"ErrorRaised 1", "ErrorCleared 2", "Stalled 2", "Removed 3"})
+		So(events[3].Torrent.ID, ShouldEqual, 3)
+		So(len(known), ShouldEqual, 2)
+	})
+
+	Convey("Test reaching the infinite ratio", t, func() {
+		known := map[int]*Torrent{1: {ID: 1, UploadRatio: -1}, 2: {ID: 2, UploadRatio: 0.5}}
+		events := diffTorrents(known, Torrents{
+			{ID: 1, UploadRatio: -2},
+			{ID: 2, UploadRatio: -1},
+		}, nil, false, 1)
+		So(len(events), ShouldEqual, 1)
+		So(events[0].Type, ShouldEqual, EventRatioReached)
+		So(events[0].ID, ShouldEqual, 1)
+	})
+}